@@ -3,7 +3,14 @@ Package circle provides a stream API.
 */
 package circle
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/berquerant/circle/internal/reflection"
+)
 
 type (
 	// StreamBuilder provides a convenient interface for streaming.
@@ -14,6 +21,13 @@ type (
 		// Convert each element by f, func(A) (B, error) or func(A) B.
 		// If f returns error, the element is filtered from this stream.
 		Map(f interface{}, opt ...StreamOption) StreamBuilder
+		// MapRetry maps stream like Map,
+		// but applies f, func(A) (B, error) or func(A) B, up to attempts times until f succeeds.
+		// If f fails attempts times, the element is filtered from this stream.
+		// WithRetryBackoff() sets the interval between the attempts.
+		// If attempts is not positive, fails to create stream.
+		// See NewRetryMapper().
+		MapRetry(f interface{}, attempts int, opt ...StreamOption) StreamBuilder
 		// MaybeMap maps stream with Maybe.
 		// If an element is Just (has value), converts the value of it by f, func(A) (B, error) or func(A) B,
 		// If f returns error, yield Nothing (has no value).
@@ -33,6 +47,9 @@ type (
 		// Select elements by f, func(A) (bool, error) or func(A) bool.
 		// If f returns false, the element is filtered from this stream.
 		// If f returns error, stops streaming.
+		// With WithResumeOnError(), the element is filtered instead.
+		// With WithCollectErrors(), the iteration continues past the errors of Map and Filter
+		// and the resulting iterator collects them, see ErrorCollector.
 		Filter(f interface{}, opt ...StreamOption) StreamBuilder
 		// TupleFilter filters stream with Tuple.
 		// Select elements by f, func(A1, A2, ..., An) (bool, error) or func(A1, A2, ..., An) bool.
@@ -40,18 +57,233 @@ type (
 		// If f returns error,
 		// or an element is not Tuple or size of Tuple is not equal to n or type of each element do not match to A1, A2, ...., An,
 		// stops streaming.
+		// With WithResumeOnError(), the element is filtered instead.
 		TupleFilter(f interface{}, opt ...StreamOption) StreamBuilder
+		// TakeWhile takes elements from the head of stream.
+		// Yield elements while f, func(A) (bool, error) or func(A) bool, returns true.
+		// If f returns false, stops streaming without consuming further elements.
+		// If f returns error, stops streaming.
+		TakeWhile(f interface{}, opt ...StreamOption) StreamBuilder
+		// DropWhile drops elements from the head of stream.
+		// Discard elements while f, func(A) (bool, error) or func(A) bool, returns true.
+		// Once f returns false, yield the element and all subsequent elements without calling f.
+		// If f returns error, stops streaming.
+		DropWhile(f interface{}, opt ...StreamOption) StreamBuilder
+		// Distinct removes duplicated elements from stream.
+		// Yield only the first occurrence of each element, the order of them is preserved.
+		// If an element is not hashable such as a slice or a map, stops streaming.
+		Distinct(opt ...StreamOption) StreamBuilder
+		// DistinctBy removes elements that have duplicated keys from stream.
+		// Extract the key of each element by f, func(A) (K, error) or func(A) K.
+		// Yield only the first element of each key, the order of them is preserved.
+		// If f returns error or a key is not hashable, stops streaming.
+		DistinctBy(f interface{}, opt ...StreamOption) StreamBuilder
+		// DistinctWindow removes elements that equal one of the last n distinct elements from stream.
+		// Unlike Distinct, this remembers only n elements, so an element can be yielded again
+		// after it falls out of the window.
+		// If an element is not hashable such as a slice or a map, stops streaming.
+		// If n is not positive, fails to create stream.
+		DistinctWindow(n int, opt ...StreamOption) StreamBuilder
+		// Dedup removes consecutive duplicated elements from stream.
+		// Yield an element only if it is not equal to the previous element, this holds only the previous element.
+		// If an element is not comparable such as a slice or a map, stops streaming.
+		Dedup(opt ...StreamOption) StreamBuilder
+		// DedupBy removes consecutive elements that have the same key from stream.
+		// Extract the key of each element by f, func(A) (K, error) or func(A) K.
+		// If f returns error or a key is not comparable, stops streaming.
+		DedupBy(f interface{}, opt ...StreamOption) StreamBuilder
+		// Intersperse yields sep between successive elements of stream,
+		// not before the first element or after the last element.
+		Intersperse(sep interface{}, opt ...StreamOption) StreamBuilder
+		// Peek observes stream.
+		// Pass each element to f, func(A) error or func(A), and yield it unchanged.
+		// If f returns error, stops streaming.
+		Peek(f interface{}, opt ...StreamOption) StreamBuilder
+		// MapError converts the error that stops the upstream by f and yields the elements unchanged.
+		// ErrEOI is not passed to f.
+		// If f returns nil, stream ends normally.
+		MapError(f func(error) error, opt ...StreamOption) StreamBuilder
+		// Recover yields the elements unchanged, and when the upstream stops with error except ErrEOI,
+		// converts the error by f, func(error) (A, error) or func(error) A, and yields the result as the last element.
+		// f is called at most once at the end of stream.
+		// If f returns error, stops streaming with the error.
+		Recover(f interface{}, opt ...StreamOption) StreamBuilder
 		// Aggregate aggregates stream.
 		// Aggregate elements by f, func(A, B) (A, error) or func(A, B) (B, error) or func(A, B) A or func(A, B) B with initial value iv.
+		// If f is func(A, A) (A, error) or func(A, A) A, aggregates by foldl
+		// unless WithAggregateType() is given.
 		Aggregate(f, iv interface{}, opt ...StreamOption) StreamBuilder
+		// AggregateWhile aggregates stream like Aggregate with foldl,
+		// f is a func(B, A) (B, error) or func(B, A) B.
+		// After each step, the accumulated value is checked by cond, func(B) (bool, error) or func(B) bool,
+		// if cond returns false, stops the aggregation and yields the accumulated value.
+		// This stops consuming stream then, so it can work on infinite stream.
+		// If f is not appropriate for foldl, fails to create stream.
+		AggregateWhile(f, iv, cond interface{}, opt ...StreamOption) StreamBuilder
+		// GroupBy groups stream.
+		// Extract the key of each element by f, func(A) (K, error) or func(A) K,
+		// and yield Tuple(K, []interface{}) that contains the elements of the key.
+		// Tuples are yielded in the order of the first occurrence of keys.
+		// If f returns error, the element is filtered from this stream.
+		// If a key is not hashable, stops streaming.
+		GroupBy(f interface{}, opt ...StreamOption) StreamBuilder
+		// Scan aggregates stream and yields every intermediate accumulated value.
+		// Aggregate elements by f, func(B, A) (B, error) or func(B, A) B with initial value iv like foldl.
+		// If f returns error, stops streaming.
+		Scan(f, iv interface{}, opt ...StreamOption) StreamBuilder
 		// Sort sorts stream.
 		// Sort elements by f, func(A, A) (bool, error) or func(A, A) bool.
+		// The sort is stable, equal elements keep their original order.
 		//
-		// Note: ignore error from f currently.
+		// Note: ignore error from f by default, see WithStrictSort().
+		// This buffers all elements, see WithMaxBuffer().
 		Sort(f interface{}, opt ...StreamOption) StreamBuilder
+		// Concat appends others to stream.
+		// Yield all elements of stream and then all elements of others sequentially.
+		Concat(others []Iterator, opt ...StreamOption) StreamBuilder
+		// Enumerate pairs each element with its index.
+		// Yield Tuple(int, A), the index starts from 0 or the value specified by WithEnumerateStart().
+		Enumerate(opt ...StreamOption) StreamBuilder
+		// Reverse reverses stream.
+		// This fully materializes stream, so it cannot work on infinite stream.
+		// See WithMaxBuffer() to limit the buffer.
+		Reverse(opt ...StreamOption) StreamBuilder
 		// Flat flattens stream.
-		// See NewFlatExecutor().
+		// See NewFlatExecutor() and WithFlatString().
 		Flat(opt ...StreamOption) StreamBuilder
+		// FlatChannel flattens stream of receivable channels.
+		// Receive from each channel until it is closed, and yield the received values sequentially.
+		// If an element is not a receivable channel, stops streaming.
+		FlatChannel(opt ...StreamOption) StreamBuilder
+		// FlatDeep flattens nested slices and arrays of stream recursively up to depth levels.
+		// If depth is negative, flattens them fully.
+		// The other elements are yielded as they are, Tuples are also flattened with WithFlatTuple().
+		// See NewFlatDeepExecutor().
+		FlatDeep(depth int, opt ...StreamOption) StreamBuilder
+		// Chunk groups stream.
+		// Yield []interface{} that contains size consecutive elements,
+		// the last chunk may be shorter than size.
+		// If size is not positive, fails to create stream.
+		Chunk(size int, opt ...StreamOption) StreamBuilder
+		// WindowAggregate aggregates each tumbling window of stream.
+		// Group size consecutive elements into a window like Chunk
+		// and yield the value aggregated by f with initial value iv like Aggregate for each window,
+		// the last window may be shorter than size.
+		// If size is not positive, fails to create stream.
+		WindowAggregate(size int, f, iv interface{}, opt ...StreamOption) StreamBuilder
+		// StepBy yields every n-th element of stream, the 0th, n-th, 2n-th, ..., and discards the rest.
+		// If n is not positive, fails to create stream.
+		StepBy(n int, opt ...StreamOption) StreamBuilder
+		// Window yields sliding windows of stream.
+		// Yield Tuple that contains size consecutive elements, advancing by one element.
+		// If stream has fewer than size elements, yield nothing.
+		// If size is not positive, fails to create stream.
+		Window(size int, opt ...StreamOption) StreamBuilder
+		// TopN selects the n largest elements of stream by f, func(A, A) (bool, error) or func(A, A) bool,
+		// that returns true if the left argument is less than the right.
+		// Yield the selected elements from the largest to the smallest without sorting all of stream.
+		// If n is not positive, fails to create stream.
+		TopN(n int, f interface{}, opt ...StreamOption) StreamBuilder
+		// BottomN selects the n smallest elements of stream by f like TopN.
+		// Yield the selected elements from the smallest to the largest.
+		// If n is not positive, fails to create stream.
+		BottomN(n int, f interface{}, opt ...StreamOption) StreamBuilder
+		// Reduce aggregates stream and returns the aggregated value.
+		// See Aggregate().
+		Reduce(f, iv interface{}, opt ...StreamOption) (interface{}, error)
+		// Reduce1 aggregates stream with foldl using the first element as the initial value,
+		// f is a func(A, A) (A, error) or func(A, A) A.
+		// Returns the aggregated value as Just, returns Nothing if stream is empty.
+		// If f is not such a function, fails to create stream.
+		// If f returns error, returns the error.
+		Reduce1(f interface{}) (Maybe, error)
+		// First returns the first element of stream as Just,
+		// returns Nothing if stream is empty.
+		// This stops consuming stream after the first element.
+		First() (Maybe, error)
+		// Last returns the last element of stream as Just,
+		// returns Nothing if stream is empty.
+		// Unlike First, this consumes all of stream, so it cannot work on infinite stream.
+		// If the iteration yields error, returns the error.
+		Last() (Maybe, error)
+		// Nth returns the n-th (0-based) element of stream as Just,
+		// returns Nothing if stream has n or fewer elements.
+		// This stops consuming stream after the n-th element.
+		// If n is negative, fails to create stream.
+		Nth(n int) (Maybe, error)
+		// Find returns the first element of stream that f, func(A) (bool, error) or func(A) bool, returns true as Just,
+		// returns Nothing if no such element.
+		// This stops consuming stream after the found element.
+		// If f returns error, returns the error.
+		Find(f interface{}) (Maybe, error)
+		// Min returns the minimum element of stream as Just,
+		// returns Nothing if stream is empty.
+		// f is a func(A, A) (bool, error) or func(A, A) bool that reports whether the left is less than the right.
+		// If f returns error, returns the error.
+		Min(f interface{}) (Maybe, error)
+		// Max returns the maximum element of stream as Just,
+		// returns Nothing if stream is empty.
+		// See Min().
+		Max(f interface{}) (Maybe, error)
+		// SumInt returns the sum of stream.
+		// Each element is converted to int, if fails, returns the conversion error.
+		SumInt() (int, error)
+		// SumFloat returns the sum of stream.
+		// Each element is converted to float64, if fails, returns the conversion error.
+		SumFloat() (float64, error)
+		// Average returns the arithmetic mean of stream.
+		// Each element is converted to float64, if fails, returns the conversion error.
+		// If stream is empty, returns ErrEmptyStream.
+		Average() (float64, error)
+		// Collect returns all elements of stream as a slice.
+		// This holds all elements in memory.
+		Collect() ([]interface{}, error)
+		// CollectMap returns a map built from stream,
+		// f is a func(A) (K, V, error) or func(A) (K, V) that returns a key and a value of an element.
+		// If keys conflict, the last value wins.
+		// Returns the first error of f or stream.
+		// If a key is not hashable, returns ErrNotHashable.
+		CollectMap(f interface{}) (map[interface{}]interface{}, error)
+		// CountBy returns the number of the elements of stream for each key.
+		// f is a func(A) (K, error) or func(A) K that returns a key of an element.
+		// If f returns error, the element is not counted.
+		// Returns the first error of stream.
+		// If a key is not hashable, returns ErrNotHashable.
+		CountBy(f interface{}) (map[interface{}]int, error)
+		// SequenceEither converts stream of Either into Either of slice.
+		// Returns Right with []interface{} of all right values if all elements are Right,
+		// else returns the first Left.
+		// This stops consuming stream at the first Left.
+		// If an element is not Either or the iteration yields error, returns Left with the error.
+		SequenceEither() Either
+		// SequenceMaybe converts stream of Maybe into Maybe of slice.
+		// Returns Just with []interface{} of all values if all elements are Just,
+		// else returns Nothing.
+		// This stops consuming stream at the first Nothing, an element that is not Maybe is regarded as Nothing.
+		// If the iteration yields error, returns the error.
+		SequenceMaybe() (Maybe, error)
+		// Partition splits stream by f, func(A) (bool, error) or func(A) bool,
+		// into the iterator of the elements that f returns true
+		// and the iterator of the elements that f returns false.
+		// All elements are buffered when either iterator is iterated first.
+		// If f returns error, both iterators end with the error.
+		Partition(f interface{}) (Iterator, Iterator, error)
+		// FlatMap maps and flattens stream.
+		// Convert each element by f, func(A) ([]B, error) or func(A) []B, and flatten the results.
+		// If f returns error, the element is filtered from this stream.
+		FlatMap(f interface{}, opt ...StreamOption) StreamBuilder
+		// MapToTuple maps stream into Tuples.
+		// Convert each element by f, func(A) (B1, B2, ..., Bn, error) or func(A) (B1, B2, ..., Bn),
+		// and yield Tuple(B1, B2, ..., Bn), so the following TupleMap or TupleConsume receives the Tuples.
+		// f must have at least one output except error, otherwise fails to create stream.
+		// If f returns error, the element is filtered from this stream.
+		MapToTuple(f interface{}, opt ...StreamOption) StreamBuilder
+		// FlatMapTuple expands each element into multiple Tuples.
+		// Convert each element by f, func(A) ([]Tuple, error) or func(A) []Tuple, and yield each Tuple,
+		// so the following TupleMap or TupleConsume receives the Tuples.
+		// If f returns error, the element is filtered from this stream.
+		// The Tuples are already flattened, a following Flat() does not expand them.
+		FlatMapTuple(f interface{}, opt ...StreamOption) StreamBuilder
 		// Consume consumes stream by f, func(A) error or func(A).
 		// If f returns error, stops consuming.
 		Consume(f interface{}, opt ...StreamOption) error
@@ -67,6 +299,44 @@ type (
 		// If an element is not Tuple or size of Tuple is not equal to n or type of each element do not match to A1, A2, ...., An
 		// or f returns error, stops consuming.
 		TupleConsume(f interface{}, opt ...StreamOption) error
+		// ConsumeIndexed consumes stream by f, func(int, A) error or func(int, A),
+		// with the zero-based index of the element.
+		// If f returns error, stops consuming.
+		ConsumeIndexed(f interface{}, opt ...StreamOption) error
+		// ConsumeBatch consumes stream by f, func([]interface{}) error or func([]interface{}),
+		// with up to size consecutive elements at a time.
+		// The last batch may be shorter than size.
+		// If f returns error, stops consuming.
+		// If size is not positive, fails to create stream.
+		ConsumeBatch(f interface{}, size int, opt ...StreamOption) error
+		// ConsumeParallel consumes stream by f, func(A) error or func(A), on workers goroutines.
+		// This is useful when f does independent I/O per element.
+		// The order of consumption is not guaranteed.
+		// The first error from f or stream cancels the other workers and is returned,
+		// the error contains the node id given by WithNodeID() or the index of the consumer.
+		// If workers is not positive, fails to create stream.
+		ConsumeParallel(f interface{}, workers int, opt ...StreamOption) error
+		// ConsumeWithContext consumes stream by f like Consume.
+		// If ctx is canceled, stops consuming and returns ctx.Err().
+		ConsumeWithContext(ctx context.Context, f interface{}, opt ...StreamOption) error
+		// ExecuteWithContext builds the stream and executes it with ctx.
+		// See Stream.ExecuteWithContext().
+		ExecuteWithContext(ctx context.Context) (Iterator, error)
+		// Build validates stream and returns a factory of the iterators of stream.
+		// Each call of the factory executes stream over the source from the beginning,
+		// so the source must be the iterator created by NewIterator() from a slice, an array or a map,
+		// otherwise returns ErrNotReiterable.
+		// The errors from the executors of stream are returned by the factory.
+		// The order of the elements of a map may differ for each call.
+		Build() (func() (Iterator, error), error)
+		// Tap returns an iterator that yields the elements flowing out of the node of nodeID for debugging,
+		// the main stream is not disturbed.
+		// nodeID is the id given by WithNodeID() or the index of the node.
+		// The iterator yields ErrEOI until stream is executed, and follows the latest execution.
+		// The elements are buffered until the tap reads them, so read the tap or the memory grows.
+		// If no such node, returns ErrNodeNotFound.
+		// See Stream.Tap().
+		Tap(nodeID string) (Iterator, error)
 		Executor
 	}
 
@@ -91,6 +361,14 @@ func (s *streamBuilder) add(f StreamFactory) StreamBuilder {
 	return s
 }
 
+// clone returns a new builder that has the same source and a copy of the nodes of s.
+func (s *streamBuilder) clone() *streamBuilder {
+	return &streamBuilder{
+		stream: s.stream,
+		nodes:  append([]StreamFactory{}, s.nodes...),
+	}
+}
+
 func (s *streamBuilder) Map(f interface{}, opt ...StreamOption) StreamBuilder {
 	x, err := NewMapper(f)
 	return s.add(func(a Stream) (Stream, error) {
@@ -109,6 +387,88 @@ func (s *streamBuilder) Filter(f interface{}, opt ...StreamOption) StreamBuilder
 		return a.Filter(x, opt...), nil
 	})
 }
+func (s *streamBuilder) TakeWhile(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewFilter(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.TakeWhile(x, opt...), nil
+	})
+}
+func (s *streamBuilder) DropWhile(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewFilter(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.DropWhile(x, opt...), nil
+	})
+}
+func (s *streamBuilder) Distinct(opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Distinct(opt...), nil
+	})
+}
+func (s *streamBuilder) DistinctWindow(n int, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		if n <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.DistinctWindow(n, opt...), nil
+	})
+}
+func (s *streamBuilder) DistinctBy(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.DistinctBy(x, opt...), nil
+	})
+}
+func (s *streamBuilder) Dedup(opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Dedup(opt...), nil
+	})
+}
+func (s *streamBuilder) DedupBy(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.DedupBy(x, opt...), nil
+	})
+}
+func (s *streamBuilder) Intersperse(sep interface{}, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Intersperse(sep, opt...), nil
+	})
+}
+func (s *streamBuilder) Peek(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewConsumer(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.Peek(x, opt...), nil
+	})
+}
+func (s *streamBuilder) MapError(f func(error) error, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.MapError(f, opt...), nil
+	})
+}
+func (s *streamBuilder) Recover(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.Recover(x, opt...), nil
+	})
+}
 func (s *streamBuilder) Aggregate(f, iv interface{}, opt ...StreamOption) StreamBuilder {
 	x, err := NewAggregator(f)
 	return s.add(func(a Stream) (Stream, error) {
@@ -118,6 +478,37 @@ func (s *streamBuilder) Aggregate(f, iv interface{}, opt ...StreamOption) Stream
 		return a.Aggregate(x, iv, opt...), nil
 	})
 }
+func (s *streamBuilder) AggregateWhile(f, iv, cond interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewAggregator(f)
+	y, cerr := NewFilter(cond)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		if cerr != nil {
+			return nil, cerr
+		}
+		return a.AggregateWhile(x, iv, y, opt...), nil
+	})
+}
+func (s *streamBuilder) GroupBy(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.GroupBy(x, opt...), nil
+	})
+}
+func (s *streamBuilder) Scan(f, iv interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewAggregator(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.Scan(x, iv, opt...), nil
+	})
+}
 func (s *streamBuilder) Sort(f interface{}, opt ...StreamOption) StreamBuilder {
 	x, err := NewComparator(f)
 	return s.add(func(a Stream) (Stream, error) {
@@ -127,11 +518,36 @@ func (s *streamBuilder) Sort(f interface{}, opt ...StreamOption) StreamBuilder {
 		return a.Sort(x, opt...), nil
 	})
 }
+func (s *streamBuilder) Concat(others []Iterator, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Concat(others, opt...), nil
+	})
+}
+func (s *streamBuilder) Enumerate(opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Enumerate(opt...), nil
+	})
+}
+func (s *streamBuilder) Reverse(opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.Reverse(opt...), nil
+	})
+}
 func (s *streamBuilder) Flat(opt ...StreamOption) StreamBuilder {
 	return s.add(func(a Stream) (Stream, error) {
 		return a.Flat(opt...), nil
 	})
 }
+func (s *streamBuilder) FlatChannel(opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.FlatChannel(opt...), nil
+	})
+}
+func (s *streamBuilder) FlatDeep(depth int, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		return a.FlatDeep(depth, opt...), nil
+	})
+}
 func (s *streamBuilder) MaybeMap(f interface{}, opt ...StreamOption) StreamBuilder {
 	x, err := NewMaybeMapper(f)
 	return s.add(func(a Stream) (Stream, error) {
@@ -168,8 +584,113 @@ func (s *streamBuilder) TupleFilter(f interface{}, opt ...StreamOption) StreamBu
 		return a.Filter(x, opt...), nil
 	})
 }
-func (s *streamBuilder) connect() (Stream, error) {
-	var st Stream = s.stream
+func (s *streamBuilder) FlatMap(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.FlatMap(x, opt...), nil
+	})
+}
+func (s *streamBuilder) MapRetry(f interface{}, attempts int, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		c := newStreamConfig(opt...)
+		r, err := NewRetryMapper(x, attempts, c.Retry.Backoff)
+		if err != nil {
+			return nil, err
+		}
+		return a.Map(r, opt...), nil
+	})
+}
+func (s *streamBuilder) MapToTuple(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewToTupleMapper(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.Map(x, opt...), nil
+	})
+}
+func (s *streamBuilder) FlatMapTuple(f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewMapper(f)
+	if err == nil && reflect.TypeOf(f).Out(0) != tupleSliceType {
+		err = ErrInvalidMapper
+	}
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.FlatMap(x, opt...), nil
+	})
+}
+
+func (s *streamBuilder) Chunk(size int, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		if size <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.Chunk(size, opt...), nil
+	})
+}
+func (s *streamBuilder) WindowAggregate(size int, f, iv interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewAggregator(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		if size <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.WindowAggregate(size, x, iv, opt...), nil
+	})
+}
+func (s *streamBuilder) TopN(n int, f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewComparator(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.TopN(n, x, opt...), nil
+	})
+}
+func (s *streamBuilder) BottomN(n int, f interface{}, opt ...StreamOption) StreamBuilder {
+	x, err := NewComparator(f)
+	return s.add(func(a Stream) (Stream, error) {
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.BottomN(n, x, opt...), nil
+	})
+}
+func (s *streamBuilder) StepBy(n int, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		if n <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.StepBy(n, opt...), nil
+	})
+}
+func (s *streamBuilder) Window(size int, opt ...StreamOption) StreamBuilder {
+	return s.add(func(a Stream) (Stream, error) {
+		if size <= 0 {
+			return nil, ErrInvalidSize
+		}
+		return a.Window(size, opt...), nil
+	})
+}
+func (s *streamBuilder) connect() (Stream, error) { return s.connectTo(s.stream) }
+func (s *streamBuilder) connectTo(st Stream) (Stream, error) {
 	for i, f := range s.nodes {
 		n, err := f(st)
 		if err != nil {
@@ -179,6 +700,67 @@ func (s *streamBuilder) connect() (Stream, error) {
 	}
 	return st, nil
 }
+
+var (
+	// ErrNotReiterable is returned by StreamBuilder.Build calls
+	// when the source of the stream cannot be iterated again.
+	ErrNotReiterable = errors.New("not reiterable")
+)
+
+// isReiterable returns true if the source of s can be iterated again.
+func (s *streamBuilder) isReiterable() bool {
+	st, ok := s.stream.(*stream)
+	if !ok {
+		return false
+	}
+	_, ok = reiterate(st.it)
+	return ok
+}
+
+func (s *streamBuilder) Build() (func() (Iterator, error), error) {
+	st, ok := s.stream.(*stream)
+	if !ok {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, ErrNotReiterable)
+	}
+	newStream := func() (Stream, error) {
+		it, ok := reiterate(st.it)
+		if !ok {
+			return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, ErrNotReiterable)
+		}
+		return s.connectTo(NewStream(it))
+	}
+	// validate the nodes
+	if _, err := newStream(); err != nil {
+		return nil, err
+	}
+	return func() (Iterator, error) {
+		x, err := newStream()
+		if err != nil {
+			return nil, err
+		}
+		return x.Execute()
+	}, nil
+}
+func (s *streamBuilder) Tap(nodeID string) (Iterator, error) {
+	// validate the node id by the nodes that are not connected yet
+	st, err := s.connectTo(NewStream(MustNewIterator(nil)))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := st.Tap(nodeID); err != nil {
+		return nil, err
+	}
+	t := &tapIterator{}
+	s.add(func(a Stream) (Stream, error) {
+		it, err := a.Tap(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		t.set(it)
+		return a, nil
+	})
+	return t, nil
+}
 func (s *streamBuilder) Execute() (Iterator, error) {
 	st, err := s.connect()
 	if err != nil {
@@ -186,6 +768,390 @@ func (s *streamBuilder) Execute() (Iterator, error) {
 	}
 	return st.Execute()
 }
+func (s *streamBuilder) ExecuteWithContext(ctx context.Context) (Iterator, error) {
+	st, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	return st.ExecuteWithContext(ctx)
+}
+func (s *streamBuilder) Reduce(f, iv interface{}, opt ...StreamOption) (interface{}, error) {
+	x, err := NewAggregator(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	st, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	return st.Reduce(x, iv, opt...)
+}
+func (s *streamBuilder) Reduce1(f interface{}) (Maybe, error) {
+	x, err := NewAggregator(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	if x.Type() != PerfectAggregatorType {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, ErrInvalidAggregateExecutor)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	iv, err := it.Next()
+	if err == ErrEOI {
+		return NewNothing(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ex, err := NewAggregateExecutor(x, it, iv, WithAggregateExecutorType(LAggregateExecutorType))
+	if err != nil {
+		return nil, err
+	}
+	ait, err := ex.Execute()
+	if err != nil {
+		return nil, err
+	}
+	v, err := ait.Next()
+	if err != nil {
+		return nil, err
+	}
+	return NewJust(v), nil
+}
+func (s *streamBuilder) First() (Maybe, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return first(it)
+}
+func (s *streamBuilder) Find(f interface{}) (Maybe, error) {
+	x, err := NewFilter(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	fit, err := NewFilterExecutor(x, it).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return first(fit)
+}
+func (s *streamBuilder) Nth(n int) (Maybe, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, ErrInvalidSize)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		if _, err := it.Next(); err != nil {
+			if err == ErrEOI {
+				return NewNothing(), nil
+			}
+			return nil, err
+		}
+	}
+	return first(it)
+}
+func (s *streamBuilder) Last() (Maybe, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	var r Maybe = NewNothing()
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return r, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		r = NewJust(x)
+	}
+}
+
+func first(it Iterator) (Maybe, error) {
+	x, err := it.Next()
+	if err == ErrEOI {
+		return NewNothing(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewJust(x), nil
+}
+
+func (s *streamBuilder) Min(f interface{}) (Maybe, error) { return s.extreme(f, false) }
+func (s *streamBuilder) Max(f interface{}) (Maybe, error) { return s.extreme(f, true) }
+func (s *streamBuilder) extreme(f interface{}, isMax bool) (Maybe, error) {
+	x, err := NewComparator(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return extreme(it, x, isMax)
+}
+
+// extreme returns the minimum or maximum element of it.
+func extreme(it Iterator, f Comparator, isMax bool) (Maybe, error) {
+	acc, err := it.Next()
+	if err == ErrEOI {
+		return NewNothing(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return NewJust(acc), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var isLess bool
+		if isMax {
+			isLess, err = f.Apply(acc, x)
+		} else {
+			isLess, err = f.Apply(x, acc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if isLess {
+			acc = x
+		}
+	}
+}
+
+var (
+	intType     = reflect.TypeOf(0)
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+var (
+	tupleSliceType     = reflect.TypeOf([]Tuple{})
+	interfaceSliceType = reflect.TypeOf([]interface{}{})
+)
+
+func (s *streamBuilder) SumInt() (int, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return 0, err
+	}
+	var acc int
+	if _, err := walkNumbers(it, intType, func(v reflect.Value) { acc += int(v.Int()) }); err != nil {
+		return 0, err
+	}
+	return acc, nil
+}
+
+func (s *streamBuilder) SumFloat() (float64, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return 0, err
+	}
+	var acc float64
+	if _, err := walkNumbers(it, float64Type, func(v reflect.Value) { acc += v.Float() }); err != nil {
+		return 0, err
+	}
+	return acc, nil
+}
+
+func (s *streamBuilder) Average() (float64, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return 0, err
+	}
+	var acc float64
+	n, err := walkNumbers(it, float64Type, func(v reflect.Value) { acc += v.Float() })
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, ErrEmptyStream
+	}
+	return acc / float64(n), nil
+}
+
+// walkNumbers converts each element of it to t and passes it to f.
+// Returns the number of the elements.
+func walkNumbers(it Iterator, t reflect.Type, f func(reflect.Value)) (int, error) {
+	var n int
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return n, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		v, err := reflection.Convert(x, t, false)
+		if err != nil {
+			return 0, err
+		}
+		f(v)
+		n++
+	}
+}
+
+func (s *streamBuilder) Collect() ([]interface{}, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return Collect(it)
+}
+
+func (s *streamBuilder) CollectMap(f interface{}) (map[interface{}]interface{}, error) {
+	x, err := NewKeyValueMapper(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return collectMap(it, x)
+}
+
+func collectMap(it Iterator, f KeyValueMapper) (ret map[interface{}]interface{}, rerr error) {
+	var key interface{}
+	defer func() {
+		if err := recover(); err != nil {
+			ret = nil
+			rerr = fmt.Errorf("%w %T", ErrNotHashable, key)
+		}
+	}()
+	m := map[interface{}]interface{}{}
+	for {
+		v, err := it.Next()
+		if err == ErrEOI {
+			return m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		k, v, err := f.Apply(v)
+		if err != nil {
+			return nil, err
+		}
+		key = k
+		m[k] = v
+	}
+}
+
+func (s *streamBuilder) CountBy(f interface{}) (map[interface{}]int, error) {
+	x, err := NewMapper(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	kit, err := NewMapExecutor(x, it).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return countBy(kit)
+}
+
+func countBy(it Iterator) (ret map[interface{}]int, rerr error) {
+	var key interface{}
+	defer func() {
+		if err := recover(); err != nil {
+			ret = nil
+			rerr = fmt.Errorf("%w %T", ErrNotHashable, key)
+		}
+	}()
+	m := map[interface{}]int{}
+	for {
+		k, err := it.Next()
+		if err == ErrEOI {
+			return m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = k
+		m[k]++
+	}
+}
+
+func (s *streamBuilder) SequenceEither() Either {
+	it, err := s.Execute()
+	if err != nil {
+		return NewLeft(err)
+	}
+	xs := []interface{}{}
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return NewRight(xs)
+		}
+		if err != nil {
+			return NewLeft(err)
+		}
+		e, ok := x.(Either)
+		if !ok {
+			return NewLeft(errNotEither)
+		}
+		v, ok := e.Right()
+		if !ok {
+			return e
+		}
+		xs = append(xs, v)
+	}
+}
+
+func (s *streamBuilder) SequenceMaybe() (Maybe, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return nil, err
+	}
+	xs := []interface{}{}
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return NewJust(xs), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		m, ok := x.(Maybe)
+		if !ok {
+			return NewNothing(), nil
+		}
+		v, ok := m.Get()
+		if !ok {
+			return NewNothing(), nil
+		}
+		xs = append(xs, v)
+	}
+}
+
+func (s *streamBuilder) Partition(f interface{}) (Iterator, Iterator, error) {
+	x, err := NewFilter(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	st, err := s.connect()
+	if err != nil {
+		return nil, nil, err
+	}
+	return st.Partition(x)
+}
 func (s *streamBuilder) consume(f func() (Consumer, error), opt ...StreamOption) error {
 	x, err := f()
 	if err != nil {
@@ -209,3 +1175,42 @@ func (s *streamBuilder) EitherConsume(f, g interface{}, opt ...StreamOption) err
 func (s *streamBuilder) TupleConsume(f interface{}, opt ...StreamOption) error {
 	return s.consume(func() (Consumer, error) { return NewTupleConsumer(f) }, opt...)
 }
+func (s *streamBuilder) ConsumeIndexed(f interface{}, opt ...StreamOption) error {
+	return s.consume(func() (Consumer, error) { return NewIndexedConsumer(f) }, opt...)
+}
+func (s *streamBuilder) ConsumeBatch(f interface{}, size int, opt ...StreamOption) error {
+	x, err := NewConsumer(f)
+	if err == nil && reflect.TypeOf(f).In(0) != interfaceSliceType {
+		err = ErrInvalidConsumer
+	}
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	st, err := s.connect()
+	if err != nil {
+		return err
+	}
+	return st.ConsumeBatch(x, size, opt...)
+}
+func (s *streamBuilder) ConsumeParallel(f interface{}, workers int, opt ...StreamOption) error {
+	x, err := NewConsumer(f)
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	st, err := s.connect()
+	if err != nil {
+		return err
+	}
+	return st.ConsumeParallel(x, workers, opt...)
+}
+func (s *streamBuilder) ConsumeWithContext(ctx context.Context, f interface{}, opt ...StreamOption) error {
+	x, err := NewConsumer(f)
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	st, err := s.connect()
+	if err != nil {
+		return err
+	}
+	return st.ConsumeWithContext(ctx, x, opt...)
+}
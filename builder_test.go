@@ -1,10 +1,12 @@
 package circle_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/berquerant/circle"
 
@@ -166,6 +168,21 @@ func ExampleStreamBuilder_tupleMap() {
 	// <nil>
 }
 
+func ExampleStreamBuilder_mapToTuple() {
+	it, _ := circle.NewIterator([]int{1, 2, 3})
+	err := circle.NewStreamBuilder(it).
+		MapToTuple(func(x int) (int, int) { return x, x * x }).
+		TupleConsume(func(x, xx int) {
+			fmt.Printf("%d %d\n", x, xx)
+		})
+	fmt.Println(err)
+	// Output:
+	// 1 1
+	// 2 4
+	// 3 9
+	// <nil>
+}
+
 func ExampleStreamBuilder_filter() {
 	it, _ := circle.NewIterator([]int{1, 2, 3, -1, 4, 5, 6})
 	err := circle.NewStreamBuilder(it).
@@ -186,6 +203,27 @@ func ExampleStreamBuilder_filter() {
 	// f1 negative: -1
 }
 
+func ExampleStreamBuilder_filterResumeOnError() {
+	it, _ := circle.NewIterator([]int{1, 2, 3, -1, 4, 5, 6})
+	err := circle.NewStreamBuilder(it).
+		Filter(func(x int) (bool, error) {
+			if x < 0 {
+				return false, fmt.Errorf("negative: %d", x)
+			}
+			return x&1 == 1, nil
+		}, circle.WithNodeID("f1"), circle.WithResumeOnError()).
+		Consume(func(x int) error {
+			fmt.Println(x)
+			return nil
+		})
+	fmt.Println(err)
+	// Output:
+	// 1
+	// 3
+	// 5
+	// <nil>
+}
+
 func ExampleStreamBuilder_aggregate() {
 	src := func() circle.Iterator {
 		it, _ := circle.NewIterator([]int{1, 2, 3})
@@ -212,6 +250,108 @@ func ExampleStreamBuilder_aggregate() {
 	// right: (1+(2+(3+iv)))
 }
 
+func ExampleStreamBuilder_groupBy() {
+	it, _ := circle.NewIterator([]string{"apple", "banana", "avocado", "cherry", "blueberry"})
+	err := circle.NewStreamBuilder(it).
+		GroupBy(func(x string) string { return x[:1] }).
+		TupleConsume(func(k string, v []interface{}) { fmt.Println(k, v) })
+	fmt.Println(err)
+	// Output:
+	// a [apple avocado]
+	// b [banana blueberry]
+	// c [cherry]
+	// <nil>
+}
+
+func ExampleStreamBuilder_reduce() {
+	it, _ := circle.NewIterator([]int{1, 2, 3})
+	v, err := circle.NewStreamBuilder(it).
+		Reduce(func(acc string, x int) string {
+			return fmt.Sprintf("(%s+%d)", acc, x)
+		}, "iv")
+	fmt.Println(v, err)
+	_, err = circle.NewStreamBuilder(circle.MustNewIterator(nil)).
+		Reduce(func(x int) int { return x }, 0)
+	fmt.Println(err)
+	// Output:
+	// (((iv+1)+2)+3) <nil>
+	// cannot create stream invalid aggregator
+}
+
+func ExampleStreamBuilder_first() {
+	v, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{3, 1, 2})).First()
+	fmt.Println(v, err)
+	v, err = circle.NewStreamBuilder(circle.MustNewIterator(nil)).First()
+	fmt.Println(v, err)
+	// Output:
+	// Just(3) <nil>
+	// Nothing <nil>
+}
+
+func ExampleStreamBuilder_last() {
+	v, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{3, 1, 2})).
+		Sort(func(x, y int) bool { return x < y }).
+		Last()
+	fmt.Println(v, err)
+	v, err = circle.NewStreamBuilder(circle.MustNewIterator(nil)).Last()
+	fmt.Println(v, err)
+	// Output:
+	// Just(3) <nil>
+	// Nothing <nil>
+}
+
+func ExampleStreamBuilder_nth() {
+	var i int
+	it, _ := circle.NewIterator(func() (interface{}, error) {
+		// infinite iterator
+		i++
+		return i, nil
+	})
+	v, err := circle.NewStreamBuilder(it).
+		Map(func(x int) int { return x * x }).
+		Nth(2)
+	fmt.Println(v, err, i)
+	v, err = circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2})).Nth(2)
+	fmt.Println(v, err)
+	// Output:
+	// Just(9) <nil> 3
+	// Nothing <nil>
+}
+
+func ExampleStreamBuilder_find() {
+	var i int
+	it, _ := circle.NewIterator(func() (interface{}, error) {
+		// infinite iterator
+		i++
+		return i, nil
+	})
+	v, err := circle.NewStreamBuilder(it).
+		Map(func(x int) int { return x * x }).
+		Find(func(x int) bool { return x > 10 })
+	fmt.Println(v, err, i)
+	// Output:
+	// Just(16) <nil> 4
+}
+
+func ExampleStreamBuilder_min() {
+	less := func(x, y int) bool { return x < y }
+	v, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{3, 1, 4, 1, 5})).Min(less)
+	fmt.Println(v, err)
+	v, err = circle.NewStreamBuilder(circle.MustNewIterator([]int{3, 1, 4, 1, 5})).Max(less)
+	fmt.Println(v, err)
+	v, err = circle.NewStreamBuilder(circle.MustNewIterator(nil)).Max(less)
+	fmt.Println(v, err)
+	_, err = circle.NewStreamBuilder(circle.MustNewIterator([]int{3, 1})).Min(func(x, y int) (bool, error) {
+		return false, errors.New("incomparable")
+	})
+	fmt.Println(err)
+	// Output:
+	// Just(1) <nil>
+	// Just(5) <nil>
+	// Nothing <nil>
+	// incomparable
+}
+
 func ExampleStreamBuilder_sort() {
 	it, _ := circle.NewIterator([]int{4, 1, 3, 2})
 	err := circle.NewStreamBuilder(it).
@@ -276,6 +416,126 @@ func ExampleStreamBuilder_flatMap() {
 	// c-3
 }
 
+func ExampleStreamBuilder_peek() {
+	it, _ := circle.NewIterator([]int{1, 2, 3})
+	err := circle.NewStreamBuilder(it).
+		Peek(func(x int) { fmt.Printf("peek %d\n", x) }).
+		Map(func(x int) int { return x * 10 }).
+		Consume(func(x int) { fmt.Println(x) })
+	fmt.Println(err)
+	// Output:
+	// peek 1
+	// 10
+	// peek 2
+	// 20
+	// peek 3
+	// 30
+	// <nil>
+}
+
+func ExampleStreamBuilder_partition() {
+	it, _ := circle.NewIterator([]int{1, 2, 3, 4, 5})
+	odd, even, _ := circle.NewStreamBuilder(it).
+		Partition(func(x int) bool { return x&1 == 1 })
+	for x := range even.Channel().C() {
+		fmt.Printf("even %d\n", x)
+	}
+	for x := range odd.Channel().C() {
+		fmt.Printf("odd %d\n", x)
+	}
+	// Output:
+	// even 2
+	// even 4
+	// odd 1
+	// odd 3
+	// odd 5
+}
+
+func ExampleStreamBuilder_window() {
+	it, _ := circle.NewIterator([]int{1, 2, 3, 4})
+	_ = circle.NewStreamBuilder(it).
+		Window(2).
+		TupleMap(func(x, y int) float64 { return float64(x+y) / 2 }).
+		Consume(func(x float64) { fmt.Println(x) })
+	// Output:
+	// 1.5
+	// 2.5
+	// 3.5
+}
+
+func ExampleStreamBuilder_flatMapSlice() {
+	it, _ := circle.NewIterator([]string{"cast a", "", "spell"})
+	_ = circle.NewStreamBuilder(it).
+		FlatMap(func(x string) ([]string, error) {
+			if x == "" {
+				return nil, errors.New("empty")
+			}
+			return strings.Split(x, " "), nil
+		}).
+		Consume(func(x string) { fmt.Println(x) })
+	// Output:
+	// cast
+	// a
+	// spell
+}
+
+func ExampleStreamBuilder_enumerate() {
+	it, _ := circle.NewIterator([]string{"one", "two", "three"})
+	_ = circle.NewStreamBuilder(it).
+		Enumerate(circle.WithEnumerateStart(1)).
+		TupleConsume(func(i int, x string) { fmt.Printf("%d: %s\n", i, x) })
+	// Output:
+	// 1: one
+	// 2: two
+	// 3: three
+}
+
+func ExampleStreamBuilder_collect() {
+	it, _ := circle.NewIterator([]int{1, 2, 3})
+	xs, err := circle.NewStreamBuilder(it).
+		Map(func(x int) int { return x * x }).
+		Collect()
+	fmt.Println(xs, err)
+	// Output:
+	// [1 4 9] <nil>
+}
+
+func ExampleStreamBuilder_collectMap() {
+	it, _ := circle.NewIterator(map[string]int{
+		"one": 1,
+		"two": 2,
+	})
+	m, err := circle.NewStreamBuilder(it).
+		CollectMap(func(t circle.Tuple) (int, string) {
+			k, _ := t.GetString(0)
+			v, _ := t.GetInt(1)
+			return v, k
+		})
+	fmt.Println(m[1], m[2], err)
+	// Output:
+	// one two <nil>
+}
+
+func ExampleStreamBuilder_topN() {
+	it, _ := circle.NewIterator([]int{5, 1, 9, 3, 7, 2, 8})
+	xs, err := circle.NewStreamBuilder(it).
+		TopN(3, func(x, y int) bool { return x < y }).
+		Collect()
+	fmt.Println(xs, err)
+	// Output:
+	// [9 8 7] <nil>
+}
+
+func ExampleStreamBuilder_intersperse() {
+	it, _ := circle.NewIterator([]string{"a", "b", "c"})
+	v, err := circle.NewStreamBuilder(it).
+		Intersperse(", ").
+		Reduce(func(acc, x string) string { return acc + x }, "")
+	fmt.Println(v, err)
+	// Output:
+	// a, b, c <nil>
+}
+
 func ExampleStreamBuilder_consume() {
 	it, _ := circle.NewIterator([]int{1, 2, 3, 4, -1, 5, 6, 7})
 	err := circle.NewStreamBuilder(it).
@@ -481,6 +741,45 @@ func TestStreamBuilderConsume(t *testing.T) {
 			},
 			want: []interface{}{"1 - one", "2 - two", "3 - three"},
 		},
+		{
+			title: "invalid indexed consumer",
+			src:   []int{1, 2, 3},
+			consume: func(it circle.Iterator, ch chan<- interface{}) error {
+				return circle.NewStreamBuilder(it).
+					ConsumeIndexed(func(x int) {
+						ch <- x
+					})
+			},
+			isError: true,
+			want:    []interface{}{},
+		},
+		{
+			title: "indexed consume",
+			src:   []string{"a", "b", "c"},
+			consume: func(it circle.Iterator, ch chan<- interface{}) error {
+				return circle.NewStreamBuilder(it).
+					ConsumeIndexed(func(i int, x string) {
+						ch <- fmt.Sprintf("%d:%s", i, x)
+					})
+			},
+			want: []interface{}{"0:a", "1:b", "2:c"},
+		},
+		{
+			title: "indexed consume error",
+			src:   []string{"a", "b", "c"},
+			consume: func(it circle.Iterator, ch chan<- interface{}) error {
+				return circle.NewStreamBuilder(it).
+					ConsumeIndexed(func(i int, x string) error {
+						if i == 1 {
+							return errors.New("stop")
+						}
+						ch <- x
+						return nil
+					})
+			},
+			isError: true,
+			want:    []interface{}{"a"},
+		},
 	} {
 		t.Run(tc.title, tc.test)
 	}
@@ -685,6 +984,403 @@ func TestStreamBuilder(t *testing.T) {
 			},
 			wantVal: []interface{}{"right(2)", "left(left)", "right(3)", "left(negative)"},
 		},
+		{
+			title: "take while",
+			src:   []int{1, 2, 3, 1, 2},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					TakeWhile(func(x int) bool { return x < 3 })
+			},
+			wantVal: []interface{}{1, 2},
+		},
+		{
+			title: "take while yield error",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					TakeWhile(func(x int) (bool, error) {
+						if x > 1 {
+							return false, errors.New("ERROR")
+						}
+						return true, nil
+					}, circle.WithNodeID("NID"))
+			},
+			wantYieldErr: errors.New("NID ERROR"),
+			wantVal:      []interface{}{1},
+		},
+		{
+			title: "drop while",
+			src:   []int{1, 2, 3, 1, 2},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DropWhile(func(x int) bool { return x < 3 })
+			},
+			wantVal: []interface{}{3, 1, 2},
+		},
+		{
+			title: "drop while yield error",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DropWhile(func(x int) (bool, error) {
+						if x > 1 {
+							return false, errors.New("ERROR")
+						}
+						return true, nil
+					}, circle.WithNodeID("NID"))
+			},
+			wantYieldErr: errors.New("NID ERROR"),
+			wantVal:      []interface{}{},
+		},
+		{
+			title: "distinct",
+			src:   []string{"a", "ring", "bug", "a", "ring", "bug", "of", "roses"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Distinct()
+			},
+			wantVal: []interface{}{"a", "ring", "bug", "of", "roses"},
+		},
+		{
+			title: "distinct not hashable",
+			src:   [][]int{{1}, {1}},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Distinct(circle.WithNodeID("NID"))
+			},
+			wantYieldErr: errors.New("NID not hashable []int"),
+			wantVal:      []interface{}{},
+		},
+		{
+			title: "distinct by",
+			src:   []circle.Tuple{circle.NewTuple(1, "a"), circle.NewTuple(2, "b"), circle.NewTuple(1, "c")},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DistinctBy(func(x circle.Tuple) int { return x.MustGet(0).(int) }).
+					TupleMap(func(_ int, y string) string { return y })
+			},
+			wantVal: []interface{}{"a", "b"},
+		},
+		{
+			title: "distinct by yield error",
+			src:   []int{1, 2, -1, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DistinctBy(func(x int) (int, error) {
+						if x < 0 {
+							return 0, errors.New("ERROR")
+						}
+						return x, nil
+					}, circle.WithNodeID("NID"))
+			},
+			wantYieldErr: errors.New("NID ERROR"),
+			wantVal:      []interface{}{1, 2},
+		},
+		{
+			title: "peek yield error",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Peek(func(x int) error {
+						if x > 1 {
+							return errors.New("ERROR")
+						}
+						return nil
+					}, circle.WithNodeID("NID"))
+			},
+			wantYieldErr: errors.New("NID ERROR"),
+			wantVal:      []interface{}{1},
+		},
+		{
+			title: "chunk",
+			src:   []int{1, 2, 3, 4, 5},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Chunk(2)
+			},
+			wantVal: []interface{}{
+				[]interface{}{1, 2},
+				[]interface{}{3, 4},
+				[]interface{}{5},
+			},
+		},
+		{
+			title: "invalid chunk",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Chunk(0)
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid size"),
+		},
+		{
+			title: "step by",
+			src:   []int{1, 2, 3, 4, 5},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					StepBy(2)
+			},
+			wantVal: []interface{}{1, 3, 5},
+		},
+		{
+			title: "invalid step by",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					StepBy(-1)
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid size"),
+		},
+		{
+			title: "window aggregate",
+			src:   []int{1, 2, 3, 4, 5},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					WindowAggregate(2, func(acc, x int) int { return acc + x }, 0)
+			},
+			wantVal: []interface{}{3, 7, 5},
+		},
+		{
+			title: "invalid window aggregate",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					WindowAggregate(0, func(acc, x int) int { return acc + x }, 0)
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid size"),
+		},
+		{
+			title: "flat deep",
+			src:   [][][]int{{{1}, {2, 3}}, {{4}}},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					FlatDeep(-1)
+			},
+			wantVal: []interface{}{1, 2, 3, 4},
+		},
+		{
+			title: "flat strings",
+			src:   []string{"ab", "c"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Flat()
+			},
+			wantVal: []interface{}{"ab", "c"},
+		},
+		{
+			title: "flat strings into runes",
+			src:   []string{"ab", "c"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Flat(circle.WithFlatString())
+			},
+			wantVal: []interface{}{'a', 'b', 'c'},
+		},
+		{
+			title: "aggregate while",
+			src: func() circle.IteratorFunc {
+				var i int
+				return func() (interface{}, error) {
+					i++
+					return i, nil
+				}
+			}(),
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					AggregateWhile(func(acc, x int) int { return acc + x }, 0, func(acc int) bool { return acc <= 10 })
+			},
+			wantVal: []interface{}{15},
+		},
+		{
+			title: "invalid aggregate while",
+			src:   []int{1},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					AggregateWhile(func(acc, x int) int { return acc + x }, 0, func(acc, x int) bool { return true })
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid filter"),
+		},
+		{
+			title: "distinct window",
+			src:   []int{1, 2, 1, 3, 1, 2, 2, 4},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DistinctWindow(2)
+			},
+			wantVal: []interface{}{1, 2, 3, 1, 2, 4},
+		},
+		{
+			title: "invalid distinct window",
+			src:   []int{1},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DistinctWindow(0)
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid size"),
+		},
+		{
+			title: "filter resume on error",
+			src:   []int{1, 2, 3, -1, 4, 5, 6},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Filter(func(x int) (bool, error) {
+						if x < 0 {
+							return false, errors.New("negative")
+						}
+						return x&1 == 0, nil
+					}, circle.WithResumeOnError())
+			},
+			wantVal: []interface{}{2, 4, 6},
+		},
+		{
+			title: "tuple filter resume on error",
+			src:   []interface{}{circle.NewTuple(1, 2), "not tuple", circle.NewTuple(3, 4), circle.NewTuple(5)},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					TupleFilter(func(x, y int) bool { return x+y > 5 }, circle.WithResumeOnError()).
+					TupleMap(func(x, y int) int { return x * y })
+			},
+			wantVal: []interface{}{12},
+		},
+		{
+			title: "invalid map to tuple",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					MapToTuple(func(int) error { return nil })
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid mapper"),
+		},
+		{
+			title: "map retry",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				var calls int
+				return circle.NewStreamBuilder(it).
+					MapRetry(func(x int) (int, error) {
+						calls++
+						if calls%3 != 0 {
+							// fails twice then succeeds
+							return 0, errors.New("transient")
+						}
+						return x * 10, nil
+					}, 3, circle.WithRetryBackoff(func(int) time.Duration { return time.Millisecond }))
+			},
+			wantVal: []interface{}{10, 20, 30},
+		},
+		{
+			title: "invalid map retry",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					MapRetry(func(x int) int { return x }, 0)
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid attempts"),
+		},
+		{
+			title: "reverse",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Reverse()
+			},
+			wantVal: []interface{}{3, 2, 1},
+		},
+		{
+			title: "scan",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Scan(func(acc, x int) int { return acc + x }, 0)
+			},
+			wantVal: []interface{}{1, 3, 6},
+		},
+		{
+			title: "scan invalid direction",
+			src:   []int{1, 2, 3},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Scan(func(x int, acc string) string { return acc }, "")
+			},
+			wantNewErr: errors.New("cannot create stream 0 invalid aggregate executor"),
+		},
+		{
+			title: "concat",
+			src:   []int{1, 2},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Map(func(x int) int { return x * 10 }).
+					Concat([]circle.Iterator{
+						circle.MustNewIterator([]int{3}),
+						circle.MustNewIterator(nil),
+						circle.MustNewIterator([]int{4, 5}),
+					})
+			},
+			wantVal: []interface{}{10, 20, 3, 4, 5},
+		},
+		{
+			title: "enumerate",
+			src:   []string{"a", "b"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Enumerate().
+					TupleMap(func(i int, x string) string { return fmt.Sprintf("%d%s", i, x) })
+			},
+			wantVal: []interface{}{"0a", "1b"},
+		},
+		{
+			title: "strict sort",
+			src:   []int{3, 1, 2},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					Sort(func(x, y int) (bool, error) {
+						if x == 3 || y == 3 {
+							return false, errors.New("cannot compare")
+						}
+						return x < y, nil
+					}, circle.WithStrictSort(), circle.WithNodeID("sort"))
+			},
+			wantYieldErr: errors.New("sort cannot compare"),
+			wantVal:      []interface{}{},
+		},
+		{
+			title: "dedup by",
+			src:   []int{1, 3, 2, 4, 5},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					DedupBy(func(x int) bool { return x%2 == 0 })
+			},
+			wantVal: []interface{}{1, 2, 5},
+		},
+		{
+			title: "flat map tuple",
+			src:   []string{"a=1,b=2", "c=3", "x"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					FlatMapTuple(func(x string) ([]circle.Tuple, error) {
+						if !strings.Contains(x, "=") {
+							return nil, errors.New("no pair")
+						}
+						ts := []circle.Tuple{}
+						for _, p := range strings.Split(x, ",") {
+							kv := strings.SplitN(p, "=", 2)
+							ts = append(ts, circle.NewTuple(kv[0], kv[1]))
+						}
+						return ts, nil
+					}).
+					TupleMap(func(k, v string) string { return k + v })
+			},
+			wantVal: []interface{}{"a1", "b2", "c3"},
+		},
+		{
+			title: "invalid flat map tuple",
+			src:   []string{"a"},
+			builder: func(it circle.Iterator) circle.StreamBuilder {
+				return circle.NewStreamBuilder(it).
+					FlatMapTuple(func(x string) []string { return []string{x} })
+			},
+			wantNewErr: errors.New("[0] cannot create stream invalid mapper"),
+		},
 		{
 			title: "flat sort aggregate",
 			src:   []interface{}{[]int{5}, []int{3, 4}, []int{1, 2}},
@@ -704,3 +1400,597 @@ func TestStreamBuilder(t *testing.T) {
 		t.Run(tc.title, tc.test)
 	}
 }
+
+func TestStreamBuilderCollectMap(t *testing.T) {
+	for name, tc := range map[string]func(t *testing.T){
+		"invalid mapper": func(t *testing.T) {
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+				CollectMap(func(x int) int { return x })
+			assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+		},
+		"last value wins": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4, 5})).
+				CollectMap(func(x int) (bool, int) { return x%2 == 0, x })
+			assert.Nil(t, err)
+			assert.Equal(t, "", cmp.Diff(map[interface{}]interface{}{
+				true:  4,
+				false: 5,
+			}, got))
+		},
+		"mapper error": func(t *testing.T) {
+			e := errors.New("error")
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3})).
+				CollectMap(func(x int) (int, int, error) {
+					if x == 2 {
+						return 0, 0, e
+					}
+					return x, x, nil
+				})
+			assert.Equal(t, e, err)
+		},
+		"not hashable": func(t *testing.T) {
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+				CollectMap(func(x int) ([]int, int) { return []int{x}, x })
+			assert.True(t, errors.Is(err, circle.ErrNotHashable))
+		},
+	} {
+		t.Run(name, tc)
+	}
+}
+
+func TestStreamBuilderCountBy(t *testing.T) {
+	for name, tc := range map[string]func(t *testing.T){
+		"invalid mapper": func(t *testing.T) {
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+				CountBy(func(x, y int) int { return x })
+			assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+		},
+		"empty": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{})).
+				CountBy(func(x int) int { return x })
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(got))
+		},
+		"count": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]string{"a", "bb", "c", "ddd", "ee", "f"})).
+				CountBy(func(x string) int { return len(x) })
+			assert.Nil(t, err)
+			assert.Equal(t, "", cmp.Diff(map[interface{}]int{
+				1: 3,
+				2: 2,
+				3: 1,
+			}, got))
+		},
+		"skip mapper error": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4, 5})).
+				CountBy(func(x int) (bool, error) {
+					if x == 2 {
+						return false, errors.New("two")
+					}
+					return x%2 == 0, nil
+				})
+			assert.Nil(t, err)
+			assert.Equal(t, "", cmp.Diff(map[interface{}]int{
+				true:  1,
+				false: 3,
+			}, got))
+		},
+		"stream error": func(t *testing.T) {
+			e := errors.New("error")
+			it, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+				return nil, e
+			}))
+			assert.Nil(t, err)
+			_, err = circle.NewStreamBuilder(it).CountBy(func(x int) int { return x })
+			assert.Equal(t, e, err)
+		},
+		"not hashable": func(t *testing.T) {
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+				CountBy(func(x int) []int { return []int{x} })
+			assert.True(t, errors.Is(err, circle.ErrNotHashable))
+		},
+	} {
+		t.Run(name, tc)
+	}
+}
+
+func TestStreamBuilderRecover(t *testing.T) {
+	newSource := func() circle.Iterator {
+		it, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, errors.New("broken")
+		}))
+		assert.Nil(t, err)
+		return it
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(newSource()).
+			Recover(func(x, y error) int { return 0 }).
+			Execute()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("no error", func(t *testing.T) {
+		var calls int
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2})).
+			Recover(func(error) int {
+				calls++
+				return -1
+			}).
+			Collect()
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2}, got))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("recover", func(t *testing.T) {
+		var calls int
+		got, err := circle.NewStreamBuilder(newSource()).
+			Map(func(x int) int { return x * 10 }).
+			Recover(func(err error) string {
+				calls++
+				return err.Error()
+			}).
+			Collect()
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{10, 20, "0 broken"}, got))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("re-error", func(t *testing.T) {
+		it, err := circle.NewStreamBuilder(newSource()).
+			Recover(func(err error) (int, error) {
+				return 0, fmt.Errorf("recover %w", err)
+			}, circle.WithNodeID("recover")).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+		assert.Equal(t, "recover recover broken", err.Error())
+	})
+}
+
+func TestStreamBuilderReduce1(t *testing.T) {
+	for name, tc := range map[string]func(t *testing.T){
+		"invalid aggregator": func(t *testing.T) {
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+				Reduce1(func(acc string, x int) string { return acc })
+			assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+		},
+		"empty": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{})).
+				Reduce1(func(x, y int) int { return x + y })
+			assert.Nil(t, err)
+			assert.True(t, got.IsNothing())
+		},
+		"single": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{3})).
+				Reduce1(func(x, y int) int { return x + y })
+			assert.Nil(t, err)
+			assert.Equal(t, 3, got.MustGet())
+		},
+		"foldl": func(t *testing.T) {
+			got, err := circle.NewStreamBuilder(circle.MustNewIterator([]string{"a", "b", "c"})).
+				Reduce1(func(x, y string) string { return fmt.Sprintf("(%s+%s)", x, y) })
+			assert.Nil(t, err)
+			assert.Equal(t, "((a+b)+c)", got.MustGet())
+		},
+		"aggregator error": func(t *testing.T) {
+			e := errors.New("error")
+			_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3})).
+				Reduce1(func(x, y int) (int, error) {
+					if y == 3 {
+						return 0, e
+					}
+					return x + y, nil
+				})
+			assert.Equal(t, e, err)
+		},
+	} {
+		t.Run(name, tc)
+	}
+}
+
+func TestStreamBuilderTap(t *testing.T) {
+	newBuilder := func() circle.StreamBuilder {
+		return circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4})).
+			Map(func(x int) int { return x * 10 }, circle.WithNodeID("tenfold")).
+			Filter(func(x int) bool { return x > 20 })
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := newBuilder().Tap("missing")
+		assert.True(t, errors.Is(err, circle.ErrNodeNotFound))
+		assert.Equal(t, "node not found missing", err.Error())
+	})
+
+	t.Run("before execution", func(t *testing.T) {
+		tap, err := newBuilder().Tap("tenfold")
+		assert.Nil(t, err)
+		_, err = tap.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	for _, tc := range []struct {
+		title   string
+		nodeID  string
+		wantTap []int
+	}{
+		{
+			title:   "named",
+			nodeID:  "tenfold",
+			wantTap: []int{10, 20, 30, 40},
+		},
+		{
+			title:   "index",
+			nodeID:  "1",
+			wantTap: []int{30, 40},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			b := newBuilder()
+			tap, err := b.Tap(tc.nodeID)
+			assert.Nil(t, err)
+			got, err := b.Collect()
+			assert.Nil(t, err)
+			assert.Equal(t, "", cmp.Diff([]interface{}{30, 40}, got))
+			gotTap, err := iteratorToInts(tap)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.wantTap, gotTap))
+		})
+	}
+}
+
+func TestStreamBuilderCollectErrors(t *testing.T) {
+	newBuilder := func() circle.StreamBuilder {
+		return circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4, 5, 6})).
+			Filter(func(x int) (bool, error) {
+				if x == 3 {
+					return false, errors.New("three")
+				}
+				return x != 4, nil
+			}, circle.WithCollectErrors(), circle.WithNodeID("f")).
+			Map(func(x int) (int, error) {
+				if x%5 == 0 {
+					return 0, errors.New("five")
+				}
+				return x * 10, nil
+			}, circle.WithCollectErrors())
+	}
+	wantErrs := []string{"f three", "1 five"}
+	errorStrings := func(errs []error) []string {
+		xs := make([]string, len(errs))
+		for i, err := range errs {
+			xs[i] = err.Error()
+		}
+		return xs
+	}
+
+	t.Run("iterator", func(t *testing.T) {
+		it, err := newBuilder().Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{10, 20, 60}, got))
+		ec, ok := it.(circle.ErrorCollector)
+		assert.True(t, ok)
+		assert.Equal(t, "", cmp.Diff(wantErrs, errorStrings(ec.Errors())))
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		it, err := newBuilder().Execute()
+		assert.Nil(t, err)
+		c := it.Channel()
+		got := []int{}
+		for x := range c.C() {
+			got = append(got, x.(int))
+		}
+		assert.Nil(t, c.Err())
+		assert.Equal(t, "", cmp.Diff([]int{10, 20, 60}, got))
+		ec, ok := c.(circle.ErrorCollector)
+		assert.True(t, ok)
+		assert.Equal(t, "", cmp.Diff(wantErrs, errorStrings(ec.Errors())))
+	})
+
+	t.Run("without option", func(t *testing.T) {
+		it, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+			Map(func(x int) int { return x }).
+			Execute()
+		assert.Nil(t, err)
+		_, ok := it.(circle.ErrorCollector)
+		assert.False(t, ok)
+	})
+}
+
+func TestStreamBuilderExecuteWithContext(t *testing.T) {
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var i int
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			i++
+			return i, nil
+		})
+		it, err := circle.NewStreamBuilder(src).
+			Map(func(x int) int { return x * 2 }).
+			ExecuteWithContext(ctx)
+		assert.Nil(t, err)
+		v, err := it.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+		cancel()
+		_, err = it.Next()
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, i)
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			return 1, nil
+		})
+		it, err := circle.NewStreamBuilder(src).
+			Filter(func(int) bool { return true }).
+			ExecuteWithContext(ctx)
+		assert.Nil(t, err)
+		c := it.Channel()
+		<-c.C()
+		cancel()
+		for range c.C() {
+		}
+	})
+}
+
+func TestStreamBuilderConsumeWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	src := circle.MustNewIterator(func() (interface{}, error) {
+		return 1, nil
+	})
+	err := circle.NewStreamBuilder(src).
+		Map(func(x int) int { return x * 2 }).
+		ConsumeWithContext(ctx, func(int) {
+			time.Sleep(time.Millisecond)
+		})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestStreamBuilderConsumeParallel(t *testing.T) {
+	t.Run("invalid workers", func(t *testing.T) {
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+			ConsumeParallel(func(int) {}, 0)
+		assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+	})
+
+	t.Run("error with node id", func(t *testing.T) {
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3})).
+			ConsumeParallel(func(x int) error {
+				if x == 2 {
+					return errors.New("two")
+				}
+				return nil
+			}, 2, circle.WithNodeID("consume"))
+		assert.Equal(t, "consume two", fmt.Sprint(err))
+	})
+
+	t.Run("error with default node id", func(t *testing.T) {
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3})).
+			Map(func(x int) int { return x * 10 }).
+			ConsumeParallel(func(x int) error {
+				if x == 20 {
+					return errors.New("twenty")
+				}
+				return nil
+			}, 2)
+		assert.Equal(t, "1 twenty", fmt.Sprint(err))
+	})
+}
+
+func TestStreamBuilderConsumeBatch(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+			ConsumeBatch(func([]interface{}) {}, 0)
+		assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+	})
+
+	t.Run("invalid consumer", func(t *testing.T) {
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).
+			ConsumeBatch(func([]int) {}, 2)
+		assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+	})
+
+	t.Run("consumed", func(t *testing.T) {
+		got := [][]interface{}{}
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4, 5})).
+			ConsumeBatch(func(xs []interface{}) error {
+				got = append(got, xs)
+				return nil
+			}, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([][]interface{}{{1, 2}, {3, 4}, {5}}, got))
+	})
+
+	t.Run("consumer error", func(t *testing.T) {
+		e := errors.New("error")
+		var calls int
+		err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4, 5})).
+			ConsumeBatch(func(xs []interface{}) error {
+				calls++
+				return e
+			}, 2)
+		assert.Equal(t, e, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestStreamBuilderBuild(t *testing.T) {
+	t.Run("not reiterable", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator(func() (interface{}, error) {
+			return nil, circle.ErrEOI
+		})).Map(func(x int) int { return x }).Build()
+		assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+		assert.True(t, strings.Contains(err.Error(), circle.ErrNotReiterable.Error()))
+	})
+
+	t.Run("invalid node", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).Chunk(0).Build()
+		assert.Equal(t, "[0] cannot create stream invalid size", err.Error())
+	})
+
+	t.Run("repeat", func(t *testing.T) {
+		var calls int
+		f, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3})).
+			Map(func(x int) int {
+				calls++
+				return x * 10
+			}).
+			Filter(func(x int) bool { return x > 10 }).
+			Build()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, calls)
+		for i := 0; i < 3; i++ {
+			it, err := f()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(it)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff([]int{20, 30}, got))
+		}
+		assert.Equal(t, 9, calls)
+	})
+}
+
+func TestStreamBuilderLast(t *testing.T) {
+	e := errors.New("error")
+	src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+		return nil, e
+	}))
+	assert.Nil(t, err)
+	_, err = circle.NewStreamBuilder(src).Last()
+	assert.True(t, errors.Is(err, e))
+}
+
+func TestStreamBuilderSequenceEither(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		got := circle.NewStreamBuilder(circle.MustNewIterator([]circle.Either{
+			circle.NewRight(1),
+			circle.NewRight(2),
+		})).SequenceEither()
+		v, ok := got.Right()
+		assert.True(t, ok)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2}, v))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got := circle.NewStreamBuilder(circle.MustNewIterator(nil)).SequenceEither()
+		v, ok := got.Right()
+		assert.True(t, ok)
+		assert.Equal(t, "", cmp.Diff([]interface{}{}, v))
+	})
+
+	t.Run("first left", func(t *testing.T) {
+		var consumed int
+		got := circle.NewStreamBuilder(circle.MustNewIterator([]circle.Either{
+			circle.NewRight(1),
+			circle.NewLeft("first"),
+			circle.NewLeft("second"),
+			circle.NewRight(2),
+		})).
+			Peek(func(circle.Either) { consumed++ }).
+			SequenceEither()
+		v, ok := got.Left()
+		assert.True(t, ok)
+		assert.Equal(t, "first", v)
+		assert.Equal(t, 2, consumed)
+	})
+
+	t.Run("not either", func(t *testing.T) {
+		got := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).SequenceEither()
+		assert.True(t, got.IsLeft())
+	})
+}
+
+func TestStreamBuilderSequenceMaybe(t *testing.T) {
+	t.Run("just", func(t *testing.T) {
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]circle.Maybe{
+			circle.NewJust(1),
+			circle.NewJust(2),
+		})).SequenceMaybe()
+		assert.Nil(t, err)
+		v, ok := got.Get()
+		assert.True(t, ok)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2}, v))
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]circle.Maybe{
+			circle.NewJust(1),
+			circle.NewNothing(),
+		})).SequenceMaybe()
+		assert.Nil(t, err)
+		assert.True(t, got.IsNothing())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		})).SequenceMaybe()
+		assert.True(t, errors.Is(err, e))
+	})
+}
+
+func TestStreamBuilderNth(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1})).Nth(-1)
+		assert.True(t, errors.Is(err, circle.ErrCannotCreateStream))
+	})
+
+	t.Run("first", func(t *testing.T) {
+		v, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2})).Nth(0)
+		assert.Nil(t, err)
+		assert.Equal(t, "Just(1)", fmt.Sprint(v))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		_, err = circle.NewStreamBuilder(src).Nth(3)
+		assert.True(t, errors.Is(err, e))
+	})
+}
+
+func TestStreamBuilderNumbers(t *testing.T) {
+	t.Run("sum int", func(t *testing.T) {
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]interface{}{1, int8(2), uint(3)})).SumInt()
+		assert.Nil(t, err)
+		assert.Equal(t, 6, got)
+	})
+
+	t.Run("sum float", func(t *testing.T) {
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]interface{}{1, 0.5, float32(0.25)})).SumFloat()
+		assert.Nil(t, err)
+		assert.Equal(t, 1.75, got)
+	})
+
+	t.Run("average", func(t *testing.T) {
+		got, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4})).Average()
+		assert.Nil(t, err)
+		assert.Equal(t, 2.5, got)
+	})
+
+	t.Run("average empty", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator(nil)).Average()
+		assert.Equal(t, circle.ErrEmptyStream, err)
+	})
+
+	t.Run("not number", func(t *testing.T) {
+		_, err := circle.NewStreamBuilder(circle.MustNewIterator([]interface{}{1, "two"})).SumInt()
+		assert.NotNil(t, err)
+		_, err = circle.NewStreamBuilder(circle.MustNewIterator([]interface{}{1, true})).SumFloat()
+		assert.NotNil(t, err)
+	})
+}
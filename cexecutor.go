@@ -1,9 +1,17 @@
 package circle
 
+import (
+	"context"
+	"sync"
+)
+
 type (
 	// ConsumeExecutor provides an interface for applying consumer function to iterator.
 	ConsumeExecutor interface {
 		ConsumeExecute() error
+		// ConsumeExecuteWithContext consumes like ConsumeExecute
+		// but stops consuming and returns ctx.Err() when ctx is canceled.
+		ConsumeExecuteWithContext(ctx context.Context) error
 	}
 )
 
@@ -23,7 +31,14 @@ func NewConsumeExecutor(f Consumer, it Iterator) ConsumeExecutor {
 }
 
 func (s *consumeExecutor) ConsumeExecute() error {
+	return s.ConsumeExecuteWithContext(context.Background())
+}
+
+func (s *consumeExecutor) ConsumeExecuteWithContext(ctx context.Context) error {
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		x, err := s.it.Next()
 		if err == ErrEOI {
 			return nil
@@ -36,3 +51,109 @@ func (s *consumeExecutor) ConsumeExecute() error {
 		}
 	}
 }
+
+// NewBatchConsumeExecutor returns a new ConsumeExecutor that applies f to []interface{}
+// that contains size consecutive elements of it.
+//
+// The last batch may be shorter than size.
+// If f returns error, stops consuming and returns the error.
+// If size is not positive, returns ErrInvalidSize.
+func NewBatchConsumeExecutor(f Consumer, it Iterator, size int) (ConsumeExecutor, error) {
+	ex, err := NewChunkExecutor(size, it)
+	if err != nil {
+		return nil, err
+	}
+	cit, err := ex.Execute()
+	if err != nil {
+		return nil, err
+	}
+	return NewConsumeExecutor(f, cit), nil
+}
+
+type (
+	parallelConsumeExecutor struct {
+		f       Consumer
+		it      Iterator
+		workers int
+	}
+)
+
+// NewParallelConsumeExecutor returns a new ConsumeExecutor that applies f on workers goroutines.
+//
+// The order of consumption is not guaranteed.
+// The first error from f or it stops all workers and is returned.
+// If workers is not positive, returns ErrInvalidWorkers.
+func NewParallelConsumeExecutor(f Consumer, it Iterator, workers int) (ConsumeExecutor, error) {
+	if workers <= 0 {
+		return nil, ErrInvalidWorkers
+	}
+	return &parallelConsumeExecutor{
+		f:       f,
+		it:      it,
+		workers: workers,
+	}, nil
+}
+
+func (s *parallelConsumeExecutor) ConsumeExecute() error {
+	return s.ConsumeExecuteWithContext(context.Background())
+}
+
+func (s *parallelConsumeExecutor) ConsumeExecuteWithContext(ctx context.Context) error {
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mux      sync.Mutex
+		firstErr error
+		c        = make(chan interface{})
+	)
+	setErr := func(err error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := range c {
+				if wctx.Err() != nil {
+					// discard the rest
+					continue
+				}
+				if err := s.f.Apply(x); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+
+loop:
+	for wctx.Err() == nil {
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			break
+		}
+		if err != nil {
+			setErr(err)
+			break
+		}
+		select {
+		case <-wctx.Done():
+			break loop
+		case c <- x:
+		}
+	}
+	close(c)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -1,8 +1,11 @@
 package circle_test
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/berquerant/circle"
 	"github.com/google/go-cmp/cmp"
@@ -91,3 +94,88 @@ func TestConsumeExecutor(t *testing.T) {
 		t.Run(tc.title, tc.test)
 	}
 }
+
+func TestConsumeExecutorWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	it, err := circle.NewIterator(circle.IteratorFunc(func() (interface{}, error) {
+		return 1, nil
+	}))
+	assert.Nil(t, err)
+	var n int
+	cf, err := circle.NewConsumer(func(x int) {
+		n += x
+		time.Sleep(time.Millisecond)
+	})
+	assert.Nil(t, err)
+	err = circle.NewConsumeExecutor(cf, it).ConsumeExecuteWithContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, n > 0)
+}
+
+func TestParallelConsumeExecutor(t *testing.T) {
+	t.Run("invalid workers", func(t *testing.T) {
+		cf, err := circle.NewConsumer(func(int) {})
+		assert.Nil(t, err)
+		_, err = circle.NewParallelConsumeExecutor(cf, circle.MustNewIterator(nil), 0)
+		assert.Equal(t, circle.ErrInvalidWorkers, err)
+	})
+
+	t.Run("consumed", func(t *testing.T) {
+		var (
+			mux sync.Mutex
+			got = map[int]bool{}
+		)
+		cf, err := circle.NewConsumer(func(x int) {
+			time.Sleep(time.Millisecond)
+			mux.Lock()
+			defer mux.Unlock()
+			got[x] = true
+		})
+		assert.Nil(t, err)
+		src := make([]int, 100)
+		want := map[int]bool{}
+		for i := range src {
+			src[i] = i
+			want[i] = true
+		}
+		ce, err := circle.NewParallelConsumeExecutor(cf, circle.MustNewIterator(src), 4)
+		assert.Nil(t, err)
+		assert.Nil(t, ce.ConsumeExecute())
+		assert.Equal(t, "", cmp.Diff(want, got))
+	})
+
+	t.Run("apply error", func(t *testing.T) {
+		e := errors.New("error")
+		cf, err := circle.NewConsumer(func(x int) error {
+			if x == 10 {
+				return e
+			}
+			return nil
+		})
+		assert.Nil(t, err)
+		src := circle.MustNewIterator(func() circle.IteratorFunc {
+			var i int
+			return func() (interface{}, error) {
+				i++
+				return i, nil
+			}
+		}())
+		ce, err := circle.NewParallelConsumeExecutor(cf, src, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, e, ce.ConsumeExecute())
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		e := errors.New("error")
+		cf, err := circle.NewConsumer(func(int) {})
+		assert.Nil(t, err)
+		src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		ce, err := circle.NewParallelConsumeExecutor(cf, src, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, e, ce.ConsumeExecute())
+	})
+}
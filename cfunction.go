@@ -4,12 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/berquerant/circle/internal/reflection"
 )
 
 var (
 	ErrApply = errors.New("apply error")
+	// ErrInvalidAttempts is returned by NewRetryMapper calls
+	// when attempts is not positive.
+	ErrInvalidAttempts = errors.New("invalid attempts")
+	// ErrApplyTimeout is returned by the Mapper from NewTimeoutMapper
+	// and the Filter from NewTimeoutFilter when the function does not return in time.
+	ErrApplyTimeout = errors.New("apply timeout")
 )
 
 type (
@@ -39,6 +47,33 @@ func (s *maybeMapper) Apply(v interface{}) (interface{}, error) {
 	return x.Map(s.f), nil
 }
 
+type (
+	maybeFlatMapper struct {
+		f Mapper
+	}
+)
+
+// NewMaybeFlatMapper returns a new Mapper for Maybe.
+//
+// If you want to convert Maybe[A] to Maybe[B], f is a func(A) (Maybe, error) or func(A) Maybe.
+//
+// If f returns error or not Maybe or argument is nothing, returns nothing.
+func NewMaybeFlatMapper(f interface{}) (Mapper, error) {
+	m, err := NewMapper(f)
+	if err != nil {
+		return nil, err
+	}
+	return &maybeFlatMapper{f: m}, nil
+}
+
+func (s *maybeFlatMapper) Apply(v interface{}) (interface{}, error) {
+	x, ok := v.(Maybe)
+	if !ok {
+		return nil, ErrApply
+	}
+	return x.FlatMap(s.f), nil
+}
+
 type (
 	eitherMapper struct {
 		f Mapper
@@ -66,9 +101,149 @@ func (s *eitherMapper) Apply(v interface{}) (interface{}, error) {
 	return x.Map(s.f), nil
 }
 
+type (
+	eitherFromMapper struct {
+		f Mapper
+	}
+)
+
+// EitherFrom returns a new Mapper that converts the result of f to Either.
+//
+// f is a func(A) (B, error) or func(A) B.
+// If f returns error, the Mapper returns Left with the error, else Right with the result.
+// See FromError().
+func EitherFrom(f interface{}) (Mapper, error) {
+	m, err := NewMapper(f)
+	if err != nil {
+		return nil, err
+	}
+	return &eitherFromMapper{f: m}, nil
+}
+
+func (s *eitherFromMapper) Apply(v interface{}) (interface{}, error) {
+	return FromError(s.f.Apply(v)), nil
+}
+
+type (
+	retryMapper struct {
+		f        Mapper
+		attempts int
+		backoff  func(attempt int) time.Duration
+	}
+)
+
+// NewRetryMapper returns a new Mapper that applies f up to attempts times until f succeeds.
+//
+// If f returns error attempts times, returns the last error.
+// If backoff is not nil, sleeps backoff(attempt) before each retry, attempt starts from 1.
+// If attempts is not positive, returns ErrInvalidAttempts.
+func NewRetryMapper(f Mapper, attempts int, backoff func(attempt int) time.Duration) (Mapper, error) {
+	if attempts <= 0 {
+		return nil, ErrInvalidAttempts
+	}
+	return &retryMapper{
+		f:        f,
+		attempts: attempts,
+		backoff:  backoff,
+	}, nil
+}
+
+func (s *retryMapper) Apply(v interface{}) (interface{}, error) {
+	var err error
+	for i := 0; i < s.attempts; i++ {
+		if i > 0 && s.backoff != nil {
+			time.Sleep(s.backoff(i))
+		}
+		var r interface{}
+		if r, err = s.f.Apply(v); err == nil {
+			return r, nil
+		}
+	}
+	return nil, err
+}
+
+// applyWithTimeout calls f on another goroutine and waits for it at most d.
+// If f does not return in time, returns ErrApplyTimeout.
+// The goroutine exits when f returns even after the timeout.
+func applyWithTimeout(f func() (interface{}, error), d time.Duration) (interface{}, error) {
+	type result struct {
+		v   interface{}
+		err error
+	}
+	// buffered so that the late result does not block the goroutine
+	c := make(chan result, 1)
+	go func() {
+		v, err := f()
+		c <- result{
+			v:   v,
+			err: err,
+		}
+	}()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case r := <-c:
+		return r.v, r.err
+	case <-timer.C:
+		return nil, ErrApplyTimeout
+	}
+}
+
+type (
+	timeoutMapper struct {
+		f Mapper
+		d time.Duration
+	}
+)
+
+// NewTimeoutMapper returns a new Mapper that returns ErrApplyTimeout
+// if f does not return within d.
+//
+// This spawns a goroutine for each call.
+// The goroutine keeps running until f returns even after the timeout, and the late result is discarded.
+func NewTimeoutMapper(f Mapper, d time.Duration) Mapper {
+	return &timeoutMapper{
+		f: f,
+		d: d,
+	}
+}
+
+func (s *timeoutMapper) Apply(v interface{}) (interface{}, error) {
+	return applyWithTimeout(func() (interface{}, error) { return s.f.Apply(v) }, s.d)
+}
+
+type (
+	timeoutFilter struct {
+		f Filter
+		d time.Duration
+	}
+)
+
+// NewTimeoutFilter returns a new Filter that returns ErrApplyTimeout
+// if f does not return within d.
+//
+// See NewTimeoutMapper().
+func NewTimeoutFilter(f Filter, d time.Duration) Filter {
+	return &timeoutFilter{
+		f: f,
+		d: d,
+	}
+}
+
+func (s *timeoutFilter) Apply(v interface{}) (bool, error) {
+	r, err := applyWithTimeout(func() (interface{}, error) { return s.f.Apply(v) }, s.d)
+	if err != nil {
+		return false, err
+	}
+	return r.(bool), nil
+}
+
 type (
 	tupleMapper struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
+		// args pools the buffers of arguments of f.
+		args sync.Pool
 	}
 )
 
@@ -81,8 +256,16 @@ func NewTupleMapper(f interface{}) (Mapper, error) {
 	if !isTupleMapper(f) {
 		return nil, ErrInvalidMapper
 	}
+	t := reflect.TypeOf(f)
 	return &tupleMapper{
-		f: f,
+		ft: t,
+		fv: reflect.ValueOf(f),
+		args: sync.Pool{
+			New: func() interface{} {
+				a := make([]reflect.Value, t.NumIn())
+				return &a
+			},
+		},
 	}, nil
 }
 
@@ -112,11 +295,19 @@ func (s *tupleMapper) Apply(v interface{}) (ret interface{}, rerr error) {
 	if !ok {
 		return nil, ErrApply
 	}
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	if x.Size() != t.NumIn() {
 		return nil, ErrApply
 	}
-	a := make([]reflect.Value, x.Size())
+	buf := s.args.Get().(*[]reflect.Value)
+	a := *buf
+	defer func() {
+		// release the arguments
+		for i := range a {
+			a[i] = reflect.Value{}
+		}
+		s.args.Put(buf)
+	}()
 	for i := 0; i < x.Size(); i++ {
 		p, ok := x.Get(i)
 		if !ok {
@@ -129,7 +320,7 @@ func (s *tupleMapper) Apply(v interface{}) (ret interface{}, rerr error) {
 		a[i] = v
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call(a)
+		r  = s.fv.Call(a)
 		r0 = r[0].Interface()
 	)
 	if len(r) == 2 {
@@ -141,9 +332,72 @@ func (s *tupleMapper) Apply(v interface{}) (ret interface{}, rerr error) {
 	return r0, nil
 }
 
+type (
+	toTupleMapper struct {
+		ft reflect.Type
+		fv reflect.Value
+		// n is the number of the outputs of f except error.
+		n int
+	}
+)
+
+// NewToTupleMapper returns a new Mapper that packs the outputs of f into Tuple.
+//
+// f is a func(A) (B1, B2, ..., Bn, error) or func(A) (B1, B2, ..., Bn), n must be positive.
+// The Mapper returns Tuple(B1, B2, ..., Bn).
+func NewToTupleMapper(f interface{}) (Mapper, error) {
+	n, ok := toTupleMapperOutputs(f)
+	if !ok {
+		return nil, ErrInvalidMapper
+	}
+	return &toTupleMapper{
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
+		n:  n,
+	}, nil
+}
+
+// toTupleMapperOutputs returns the number of the outputs of f except error.
+func toTupleMapperOutputs(f interface{}) (int, bool) {
+	t := reflect.TypeOf(f)
+	if !(t.Kind() == reflect.Func && t.NumIn() == 1) {
+		return 0, false
+	}
+	n := t.NumOut()
+	if n > 0 && t.Out(n-1).String() == "error" {
+		n--
+	}
+	return n, n > 0
+}
+
+func (s *toTupleMapper) Apply(v interface{}) (ret interface{}, rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			ret = nil
+			rerr = fmt.Errorf("%w %s", ErrApply, err)
+		}
+	}()
+	av, err := reflection.Convert(v, s.ft.In(0), true)
+	if err != nil {
+		return nil, err
+	}
+	r := s.fv.Call([]reflect.Value{av})
+	if len(r) > s.n {
+		if err, ok := r[s.n].Interface().(error); ok {
+			return nil, err
+		}
+	}
+	xs := make([]interface{}, s.n)
+	for i := range xs {
+		xs[i] = r[i].Interface()
+	}
+	return NewTuple(xs...), nil
+}
+
 type (
 	tupleFilter struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -157,7 +411,8 @@ func NewTupleFilter(f interface{}) (Filter, error) {
 		return nil, ErrInvalidFilter
 	}
 	return &tupleFilter{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -187,7 +442,7 @@ func (s *tupleFilter) Apply(v interface{}) (ret bool, rerr error) {
 	if !ok {
 		return false, ErrApply
 	}
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	if x.Size() != t.NumIn() {
 		return false, ErrApply
 	}
@@ -204,7 +459,7 @@ func (s *tupleFilter) Apply(v interface{}) (ret bool, rerr error) {
 		a[i] = v
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call(a)
+		r  = s.fv.Call(a)
 		r0 = r[0].Bool()
 	)
 	if len(r) == 2 {
@@ -218,7 +473,8 @@ func (s *tupleFilter) Apply(v interface{}) (ret bool, rerr error) {
 
 type (
 	tupleConsumer struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -232,7 +488,8 @@ func NewTupleConsumer(f interface{}) (Consumer, error) {
 		return nil, ErrInvalidConsumer
 	}
 	return &tupleConsumer{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -261,7 +518,7 @@ func (s *tupleConsumer) Apply(v interface{}) (rerr error) {
 	if !ok {
 		return ErrApply
 	}
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	if x.Size() != t.NumIn() {
 		return ErrApply
 	}
@@ -278,7 +535,7 @@ func (s *tupleConsumer) Apply(v interface{}) (rerr error) {
 		a[i] = v
 	}
 	var (
-		r = reflect.ValueOf(s.f).Call(a)
+		r = s.fv.Call(a)
 	)
 	if len(r) == 1 {
 		r0 := r[0].Interface()
@@ -289,6 +546,51 @@ func (s *tupleConsumer) Apply(v interface{}) (rerr error) {
 	return nil
 }
 
+type (
+	indexedConsumer struct {
+		f Consumer
+		i int
+	}
+)
+
+// NewIndexedConsumer returns a new Consumer that passes the zero-based index of the element with the element.
+// f is a func(int, A) error or func(int, A).
+//
+// The index is the number of Apply calls so far, so the Consumer should not be shared.
+func NewIndexedConsumer(f interface{}) (Consumer, error) {
+	if !isIndexedConsumer(f) {
+		return nil, ErrInvalidConsumer
+	}
+	g, err := NewTupleConsumer(f)
+	if err != nil {
+		return nil, err
+	}
+	return &indexedConsumer{
+		f: g,
+	}, nil
+}
+
+func isIndexedConsumer(f interface{}) bool {
+	t := reflect.TypeOf(f)
+	if !(t.Kind() == reflect.Func && t.NumIn() == 2 && t.In(0).Kind() == reflect.Int) {
+		return false
+	}
+	switch t.NumOut() {
+	case 0:
+		return true
+	case 1:
+		return t.Out(0).String() == "error"
+	default:
+		return false
+	}
+}
+
+func (s *indexedConsumer) Apply(x interface{}) error {
+	i := s.i
+	s.i++
+	return s.f.Apply(NewTuple(i, x))
+}
+
 type (
 	maybeConsumer struct {
 		fj Consumer
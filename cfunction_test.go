@@ -3,7 +3,9 @@ package circle_test
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/berquerant/circle"
 
@@ -69,6 +71,51 @@ func TestMaybeMapper(t *testing.T) {
 	}
 }
 
+func TestMaybeFlatMapper(t *testing.T) {
+	f, err := circle.NewMaybeFlatMapper(func(x int) circle.Maybe {
+		if x < 0 {
+			return circle.NewNothing()
+		}
+		return circle.NewJust(x + 1)
+	})
+	assert.Nil(t, err)
+	t.Run("not maybe", func(t *testing.T) {
+		_, err := f.Apply(1)
+		assert.NotNil(t, err)
+	})
+	for _, tc := range []struct {
+		title string
+		arg   circle.Maybe
+		want  circle.Maybe
+	}{
+		{
+			title: "nothing",
+			arg:   circle.NewNothing(),
+			want:  circle.NewNothing(),
+		},
+		{
+			title: "ok",
+			arg:   circle.NewJust(1),
+			want:  circle.NewJust(2),
+		},
+		{
+			title: "to nothing",
+			arg:   circle.NewJust(-1),
+			want:  circle.NewNothing(),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			v, err := f.Apply(tc.arg)
+			assert.Nil(t, err)
+			gotVal, gotOK := v.(circle.Maybe).Get()
+			wantVal, wantOK := tc.want.Get()
+			assert.Equal(t, wantOK, gotOK)
+			assert.Equal(t, wantVal, gotVal)
+		})
+	}
+}
+
 type (
 	testcaseMaybeConsumer struct {
 		title        string
@@ -597,3 +644,206 @@ func TestTupleFilter(t *testing.T) {
 		t.Run(tc.title, tc.test)
 	}
 }
+
+func TestIndexedConsumer(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		for _, f := range []interface{}{
+			func(int) {},
+			func(string, int) {},
+			func(int, int) int { return 0 },
+		} {
+			_, err := circle.NewIndexedConsumer(f)
+			assert.Equal(t, circle.ErrInvalidConsumer, err)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		got := []string{}
+		f, err := circle.NewIndexedConsumer(func(i int, x string) {
+			got = append(got, fmt.Sprintf("%d:%s", i, x))
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, f.Apply("a"))
+		assert.NotNil(t, f.Apply(1))
+		assert.Nil(t, f.Apply("c"))
+		assert.Equal(t, []string{"0:a", "2:c"}, got)
+	})
+}
+
+func TestEitherFrom(t *testing.T) {
+	f, err := circle.EitherFrom(strconv.Atoi)
+	assert.Nil(t, err)
+
+	t.Run("right", func(t *testing.T) {
+		v, err := f.Apply("10")
+		assert.Nil(t, err)
+		assert.Equal(t, "Right(10)", fmt.Sprint(v))
+	})
+
+	t.Run("left", func(t *testing.T) {
+		v, err := f.Apply("x")
+		assert.Nil(t, err)
+		assert.True(t, v.(circle.Either).IsLeft())
+	})
+
+	t.Run("from error", func(t *testing.T) {
+		xs, err := circle.NewStreamBuilder(circle.MustNewIterator([]string{"1", "x"})).
+			Map(func(x string) circle.Either { return circle.FromError(strconv.Atoi(x)) }).
+			EitherMap(func(x int) int { return x * 10 }).
+			Collect()
+		assert.Nil(t, err)
+		assert.Equal(t, "Right(10)", fmt.Sprint(xs[0]))
+		assert.True(t, xs[1].(circle.Either).IsLeft())
+	})
+}
+
+func TestRetryMapper(t *testing.T) {
+	newFlaky := func(fails int) (circle.Mapper, *int) {
+		var calls int
+		f, err := circle.NewMapper(func(x int) (int, error) {
+			calls++
+			if calls <= fails {
+				return 0, fmt.Errorf("fail %d", calls)
+			}
+			return x * 10, nil
+		})
+		assert.Nil(t, err)
+		return f, &calls
+	}
+
+	t.Run("invalid attempts", func(t *testing.T) {
+		f, _ := newFlaky(0)
+		_, err := circle.NewRetryMapper(f, 0, nil)
+		assert.Equal(t, circle.ErrInvalidAttempts, err)
+	})
+
+	t.Run("succeed after retries", func(t *testing.T) {
+		f, calls := newFlaky(2)
+		backoffs := []int{}
+		r, err := circle.NewRetryMapper(f, 3, func(attempt int) time.Duration {
+			backoffs = append(backoffs, attempt)
+			return 0
+		})
+		assert.Nil(t, err)
+		v, err := r.Apply(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, v)
+		assert.Equal(t, 3, *calls)
+		assert.Equal(t, []int{1, 2}, backoffs)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		f, calls := newFlaky(2)
+		r, err := circle.NewRetryMapper(f, 2, nil)
+		assert.Nil(t, err)
+		_, err = r.Apply(1)
+		assert.Equal(t, "fail 2", err.Error())
+		assert.Equal(t, 2, *calls)
+	})
+}
+
+func TestTimeoutMapper(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	f, err := circle.NewMapper(func(x int) int {
+		if x < 0 {
+			<-release
+			defer close(done)
+		}
+		return x * 2
+	})
+	assert.Nil(t, err)
+	m := circle.NewTimeoutMapper(f, 50*time.Millisecond)
+
+	v, err := m.Apply(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v)
+
+	_, err = m.Apply(-1)
+	assert.Equal(t, circle.ErrApplyTimeout, err)
+	// the late result does not block the goroutine
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the goroutine did not exit")
+	}
+}
+
+func TestTimeoutFilter(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	f, err := circle.NewFilter(func(x int) bool {
+		if x < 0 {
+			<-release
+		}
+		return x%2 == 0
+	})
+	assert.Nil(t, err)
+	ft := circle.NewTimeoutFilter(f, 50*time.Millisecond)
+
+	v, err := ft.Apply(2)
+	assert.Nil(t, err)
+	assert.True(t, v)
+	v, err = ft.Apply(1)
+	assert.Nil(t, err)
+	assert.False(t, v)
+	_, err = ft.Apply(-1)
+	assert.Equal(t, circle.ErrApplyTimeout, err)
+}
+
+func TestToTupleMapper(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		for _, f := range []interface{}{
+			func(int) error { return nil },
+			func() (int, int) { return 0, 0 },
+			func(int) {},
+			1,
+		} {
+			_, err := circle.NewToTupleMapper(f)
+			assert.Equal(t, circle.ErrInvalidMapper, err)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		f, err := circle.NewToTupleMapper(func(x int) int { return x * 2 })
+		assert.Nil(t, err)
+		v, err := f.Apply(2)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{4}, v.(circle.Tuple).ToSlice())
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		f, err := circle.NewToTupleMapper(strconv.Atoi)
+		assert.Nil(t, err)
+		_, err = f.Apply("x")
+		assert.NotNil(t, err)
+		g, err := circle.NewToTupleMapper(func(x int) (int, string, error) {
+			return x, fmt.Sprint(x), nil
+		})
+		assert.Nil(t, err)
+		v, err := g.Apply(1)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{1, "1"}, v.(circle.Tuple).ToSlice())
+	})
+}
+
+func BenchmarkTupleMapperApply(b *testing.B) {
+	f, err := circle.NewTupleMapper(func(x, y, z int) int { return x + y + z })
+	if err != nil {
+		b.Fatal(err)
+	}
+	xs := make([]circle.Tuple, 1024)
+	for i := range xs {
+		xs[i] = circle.NewTuple(i, i+1, i+2)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Apply(xs[i%len(xs)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
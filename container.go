@@ -1,9 +1,13 @@
 package circle
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+
+	"github.com/berquerant/circle/internal/reflection"
 )
 
 type (
@@ -20,16 +24,40 @@ type (
 		// GetOrElse returns the value of this if this is not nothing,
 		// else returns v.
 		GetOrElse(v interface{}) interface{}
+		// GetOrElseFunc returns the value of this if this is not nothing,
+		// else returns the result of f.
+		// f is called only if this is nothing.
+		GetOrElseFunc(f func() interface{}) interface{}
 		// OrElse returns this if this is not nothing,
 		// else returns v.
 		OrElse(v Maybe) Maybe
+		// OrElseFunc returns this if this is not nothing,
+		// else returns the result of f.
+		// f is called only if this is nothing.
+		OrElseFunc(f func() Maybe) Maybe
 		// Map applies f to the value of this if this is not nothing.
 		Map(f Mapper) Maybe
+		// FlatMap applies f, that returns Maybe, to the value of this if this is not nothing.
+		// If f returns error or not Maybe, returns nothing.
+		FlatMap(f Mapper) Maybe
 		// Filter applies f to the value of this if this is not nothing.
 		Filter(f Filter) Maybe
+		// FilterOrError applies f to the value of this if this is not nothing like Filter,
+		// but returns nothing and the error if f returns error.
+		FilterOrError(f Filter) (Maybe, error)
 		// Consume applies f to the value of this if this is not nothing,
 		// else calls g.
 		Consume(f, g Consumer) error
+		// ForEach applies f to the value of this if this is not nothing,
+		// else does nothing.
+		ForEach(f Consumer) error
+		// Fold returns the result of f applied to the value of this if this is not nothing,
+		// else returns ifNothing.
+		Fold(ifNothing interface{}, f Mapper) (interface{}, error)
+		// ToEither converts this to Either.
+		// If this is not nothing, returns Right,
+		// else returns Left with leftIfNothing.
+		ToEither(leftIfNothing interface{}) Either
 	}
 
 	just struct {
@@ -50,11 +78,13 @@ func NewJust(v interface{}) Maybe { return &just{v: v} }
 // NewNothing returns a new Maybe tha has no value.
 func NewNothing() Maybe { return nothingEntity }
 
-func (*just) IsNothing() bool                       { return false }
-func (s *just) Get() (interface{}, bool)            { return s.v, true }
-func (s *just) MustGet() interface{}                { return s.v }
-func (s *just) GetOrElse(v interface{}) interface{} { return s.v }
-func (s *just) OrElse(_ Maybe) Maybe                { return s }
+func (*just) IsNothing() bool                                { return false }
+func (s *just) Get() (interface{}, bool)                     { return s.v, true }
+func (s *just) MustGet() interface{}                         { return s.v }
+func (s *just) GetOrElse(v interface{}) interface{}          { return s.v }
+func (s *just) GetOrElseFunc(func() interface{}) interface{} { return s.v }
+func (s *just) OrElse(_ Maybe) Maybe                         { return s }
+func (s *just) OrElseFunc(func() Maybe) Maybe                { return s }
 func (s *just) Map(f Mapper) Maybe {
 	v, err := f.Apply(s.v)
 	if err != nil {
@@ -62,24 +92,54 @@ func (s *just) Map(f Mapper) Maybe {
 	}
 	return &just{v: v}
 }
+func (s *just) FlatMap(f Mapper) Maybe {
+	v, err := f.Apply(s.v)
+	if err != nil {
+		return nothingEntity
+	}
+	if x, ok := v.(Maybe); ok {
+		return x
+	}
+	return nothingEntity
+}
 func (s *just) Filter(f Filter) Maybe {
 	if ok, err := f.Apply(s.v); ok && err == nil {
 		return s
 	}
 	return nothingEntity
 }
-func (s *just) Consume(f, _ Consumer) error { return f.Apply(s.v) }
-func (s *just) String() string              { return fmt.Sprintf("Just(%v)", s.v) }
+func (s *just) FilterOrError(f Filter) (Maybe, error) {
+	ok, err := f.Apply(s.v)
+	if err != nil {
+		return nothingEntity, err
+	}
+	if ok {
+		return s, nil
+	}
+	return nothingEntity, nil
+}
+func (s *just) ForEach(f Consumer) error                          { return f.Apply(s.v) }
+func (s *just) Consume(f, _ Consumer) error                       { return f.Apply(s.v) }
+func (s *just) Fold(_ interface{}, f Mapper) (interface{}, error) { return f.Apply(s.v) }
+func (s *just) ToEither(interface{}) Either                       { return &right{v: s.v} }
+func (s *just) String() string                                    { return fmt.Sprintf("Just(%v)", s.v) }
 
-func (*nothing) IsNothing() bool                     { return true }
-func (*nothing) Get() (interface{}, bool)            { return nil, false }
-func (*nothing) MustGet() interface{}                { panic(errCannotGetNothing) }
-func (*nothing) GetOrElse(v interface{}) interface{} { return v }
-func (*nothing) OrElse(v Maybe) Maybe                { return v }
-func (*nothing) Map(Mapper) Maybe                    { return nothingEntity }
-func (*nothing) Filter(Filter) Maybe                 { return nothingEntity }
-func (*nothing) Consume(_, g Consumer) error         { return g.Apply(nothingEntity) }
-func (*nothing) String() string                      { return "Nothing" }
+func (*nothing) IsNothing() bool                                   { return true }
+func (*nothing) Get() (interface{}, bool)                          { return nil, false }
+func (*nothing) MustGet() interface{}                              { panic(errCannotGetNothing) }
+func (*nothing) GetOrElse(v interface{}) interface{}               { return v }
+func (*nothing) GetOrElseFunc(f func() interface{}) interface{}    { return f() }
+func (*nothing) OrElse(v Maybe) Maybe                              { return v }
+func (*nothing) OrElseFunc(f func() Maybe) Maybe                   { return f() }
+func (*nothing) Map(Mapper) Maybe                                  { return nothingEntity }
+func (*nothing) FlatMap(Mapper) Maybe                              { return nothingEntity }
+func (*nothing) Filter(Filter) Maybe                               { return nothingEntity }
+func (*nothing) FilterOrError(Filter) (Maybe, error)               { return nothingEntity, nil }
+func (*nothing) ForEach(Consumer) error                            { return nil }
+func (*nothing) Consume(_, g Consumer) error                       { return g.Apply(nothingEntity) }
+func (*nothing) Fold(v interface{}, _ Mapper) (interface{}, error) { return v, nil }
+func (*nothing) ToEither(v interface{}) Either                     { return &left{v: v} }
+func (*nothing) String() string                                    { return "Nothing" }
 
 type (
 	// Either contains successful right or failed left value.
@@ -102,9 +162,16 @@ type (
 		MustRight() interface{}
 		// GetOrElse returns right value if this is right else returns v.
 		GetOrElse(v interface{}) interface{}
+		// GetOrElseFunc returns right value if this is right else returns the result of f.
+		// f is called only if this is left.
+		GetOrElseFunc(f func() interface{}) interface{}
 		// Map applies f to value if this is right.
 		// If f returns error, returns left.
 		Map(f Mapper) Either
+		// FlatMap applies f, that returns Either, to value if this is right.
+		// If f returns error, returns left with the error.
+		// If f returns not Either, returns left.
+		FlatMap(f Mapper) Either
 		// ToMaybe converts this to Maybe.
 		// If this is right, returns Just,
 		// else returns Nothing.
@@ -112,6 +179,19 @@ type (
 		// Consume applies g to this if this is right,
 		// else f.
 		Consume(f, g Consumer) error
+		// ForEach applies f to right value if this is right,
+		// else does nothing.
+		ForEach(f Consumer) error
+		// Swap returns Left if this is right,
+		// else returns Right.
+		Swap() Either
+		// Fold returns the result of right applied to value if this is right,
+		// else returns the result of left applied to value.
+		Fold(left, right Mapper) (interface{}, error)
+		// ToError returns left value as error if this is left,
+		// else returns nil.
+		// If left value is not an error, returns ErrLeft with the value.
+		ToError() error
 	}
 
 	left struct {
@@ -125,6 +205,12 @@ type (
 var (
 	errCannotGetLeft  = errors.New("cannot get left")
 	errCannotGetRight = errors.New("cannot get right")
+	errNotEither      = errors.New("not either")
+)
+
+var (
+	// ErrLeft is returned by Either.ToError calls when the left value is not an error.
+	ErrLeft = errors.New("left")
 )
 
 // NewRight returns a new Right.
@@ -133,25 +219,46 @@ func NewRight(v interface{}) Either { return &right{v: v} }
 // NewLeft returns a new Left.
 func NewLeft(v interface{}) Either { return &left{v: v} }
 
-func (*left) IsLeft() bool                        { return true }
-func (*left) IsRight() bool                       { return false }
-func (s *left) Left() (interface{}, bool)         { return s.v, true }
-func (s *left) MustLeft() interface{}             { return s.v }
-func (s *left) Right() (interface{}, bool)        { return nil, false }
-func (*left) MustRight() interface{}              { panic(errCannotGetRight) }
-func (*left) GetOrElse(v interface{}) interface{} { return v }
-func (s *left) Map(f Mapper) Either               { return s }
-func (*left) ToMaybe() Maybe                      { return nothingEntity }
-func (s *left) Consume(f, _ Consumer) error       { return f.Apply(s.v) }
-func (s *left) String() string                    { return fmt.Sprintf("Left(%v)", s.v) }
-
-func (*right) IsLeft() bool                        { return false }
-func (*right) IsRight() bool                       { return true }
-func (*right) Left() (interface{}, bool)           { return nil, false }
-func (*right) MustLeft() interface{}               { panic(errCannotGetLeft) }
-func (s *right) Right() (interface{}, bool)        { return s.v, true }
-func (s *right) MustRight() interface{}            { return s.v }
-func (s *right) GetOrElse(interface{}) interface{} { return s.v }
+// FromError returns a new Either from the result of a func(A) (B, error) like call.
+// If err is not nil, returns Left with err, else returns Right with v.
+func FromError(v interface{}, err error) Either {
+	if err != nil {
+		return &left{v: err}
+	}
+	return &right{v: v}
+}
+
+func (*left) IsLeft() bool                                   { return true }
+func (*left) IsRight() bool                                  { return false }
+func (s *left) Left() (interface{}, bool)                    { return s.v, true }
+func (s *left) MustLeft() interface{}                        { return s.v }
+func (s *left) Right() (interface{}, bool)                   { return nil, false }
+func (*left) MustRight() interface{}                         { panic(errCannotGetRight) }
+func (*left) GetOrElse(v interface{}) interface{}            { return v }
+func (*left) GetOrElseFunc(f func() interface{}) interface{} { return f() }
+func (s *left) Map(f Mapper) Either                          { return s }
+func (s *left) FlatMap(Mapper) Either                        { return s }
+func (*left) ToMaybe() Maybe                                 { return nothingEntity }
+func (*left) ForEach(Consumer) error                         { return nil }
+func (s *left) Consume(f, _ Consumer) error                  { return f.Apply(s.v) }
+func (s *left) Fold(f, _ Mapper) (interface{}, error)        { return f.Apply(s.v) }
+func (s *left) Swap() Either                                 { return &right{v: s.v} }
+func (s *left) String() string                               { return fmt.Sprintf("Left(%v)", s.v) }
+func (s *left) ToError() error {
+	if err, ok := s.v.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%w %v", ErrLeft, s.v)
+}
+
+func (*right) IsLeft() bool                                   { return false }
+func (*right) IsRight() bool                                  { return true }
+func (*right) Left() (interface{}, bool)                      { return nil, false }
+func (*right) MustLeft() interface{}                          { panic(errCannotGetLeft) }
+func (s *right) Right() (interface{}, bool)                   { return s.v, true }
+func (s *right) MustRight() interface{}                       { return s.v }
+func (s *right) GetOrElse(interface{}) interface{}            { return s.v }
+func (s *right) GetOrElseFunc(func() interface{}) interface{} { return s.v }
 func (s *right) Map(f Mapper) Either {
 	v, err := f.Apply(s.v)
 	if err != nil {
@@ -159,9 +266,91 @@ func (s *right) Map(f Mapper) Either {
 	}
 	return &right{v: v}
 }
-func (s *right) ToMaybe() Maybe              { return &just{v: s.v} }
-func (s *right) Consume(_, g Consumer) error { return g.Apply(s.v) }
-func (s *right) String() string              { return fmt.Sprintf("Right(%v)", s.v) }
+func (s *right) FlatMap(f Mapper) Either {
+	v, err := f.Apply(s.v)
+	if err != nil {
+		return &left{v: err}
+	}
+	if x, ok := v.(Either); ok {
+		return x
+	}
+	return &left{v: errNotEither}
+}
+func (s *right) ToMaybe() Maybe                        { return &just{v: s.v} }
+func (s *right) ForEach(f Consumer) error              { return f.Apply(s.v) }
+func (s *right) Consume(_, g Consumer) error           { return g.Apply(s.v) }
+func (s *right) Fold(_, g Mapper) (interface{}, error) { return g.Apply(s.v) }
+func (s *right) Swap() Either                          { return &left{v: s.v} }
+func (*right) ToError() error                          { return nil }
+func (s *right) String() string                        { return fmt.Sprintf("Right(%v)", s.v) }
+
+var (
+	// ErrInvalidEitherJSON is returned by UnmarshalEither calls
+	// when the JSON is not an object that has only "left" or "right".
+	ErrInvalidEitherJSON = errors.New("invalid either json")
+)
+
+// MarshalJSON encodes Just as its value.
+func (s *just) MarshalJSON() ([]byte, error) { return json.Marshal(s.v) }
+
+// MarshalJSON encodes Nothing as null.
+func (*nothing) MarshalJSON() ([]byte, error) { return []byte("null"), nil }
+
+// UnmarshalMaybe decodes the JSON encoded by the Maybe.
+//
+// null is decoded to Nothing, so Just(nil) turns into Nothing.
+// The value of Just is decoded as interface{} like json.Unmarshal does.
+func UnmarshalMaybe(data []byte) (Maybe, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nothingEntity, nil
+	}
+	return &just{v: v}, nil
+}
+
+// eitherJSONValue returns the value for JSON, an error is encoded as its message.
+func eitherJSONValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+// MarshalJSON encodes Left as {"left":value}.
+// If the value is an error, the value is encoded as the message of the error.
+func (s *left) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"left": eitherJSONValue(s.v)})
+}
+
+// MarshalJSON encodes Right as {"right":value}.
+// If the value is an error, the value is encoded as the message of the error.
+func (s *right) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"right": eitherJSONValue(s.v)})
+}
+
+// UnmarshalEither decodes the JSON encoded by the Either.
+//
+// The value is decoded as interface{} like json.Unmarshal does.
+// If data is not an object that has only "left" or "right", returns ErrInvalidEitherJSON.
+func UnmarshalEither(data []byte) (Either, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if len(m) != 1 {
+		return nil, ErrInvalidEitherJSON
+	}
+	if v, ok := m["left"]; ok {
+		return &left{v: v}, nil
+	}
+	if v, ok := m["right"]; ok {
+		return &right{v: v}, nil
+	}
+	return nil, ErrInvalidEitherJSON
+}
 
 type (
 	// Tuple is an immutable array.
@@ -174,6 +363,30 @@ type (
 		// MustGet returns an element.
 		// If i is out of range then panic.
 		MustGet(i int) interface{}
+		// GetAs returns an element converted to t.
+		// If i is out of range or the element cannot be converted to t, returns false.
+		GetAs(i int, t reflect.Type) (interface{}, bool)
+		// GetString returns an element as a string.
+		// If i is out of range or the element is not a string, returns false.
+		GetString(i int) (string, bool)
+		// GetInt returns an element as an int.
+		// If i is out of range or the element cannot be converted to an int, returns false.
+		GetInt(i int) (int, bool)
+		// ToSlice returns a copy of the elements of this.
+		ToSlice() []interface{}
+		// Append returns a new Tuple that has the elements of this and vs.
+		Append(vs ...interface{}) Tuple
+		// Concat returns a new Tuple that has the elements of this and other.
+		Concat(other Tuple) Tuple
+		// Head returns the first element.
+		// If this is empty, returns false.
+		Head() (interface{}, bool)
+		// Tail returns a new Tuple that has the elements of this except the first one.
+		// If this is empty, returns an empty Tuple.
+		Tail() Tuple
+		// Map returns a new Tuple that has the elements of this converted by f.
+		// If f returns error, returns the error.
+		Map(f Mapper) (Tuple, error)
 	}
 
 	tuple struct {
@@ -192,6 +405,67 @@ func (s *tuple) Get(i int) (interface{}, bool) {
 	return s.v[i], true
 }
 func (s *tuple) MustGet(i int) interface{} { return s.v[i] }
+func (s *tuple) GetAs(i int, t reflect.Type) (interface{}, bool) {
+	x, ok := s.Get(i)
+	if !ok {
+		return nil, false
+	}
+	v, err := reflection.Convert(x, t, false)
+	if err != nil {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+func (s *tuple) GetString(i int) (string, bool) {
+	x, ok := s.Get(i)
+	if !ok || reflect.TypeOf(x) == nil || reflect.TypeOf(x).Kind() != reflect.String {
+		// avoid converting an integer into a string as a rune
+		return "", false
+	}
+	v, ok := s.GetAs(i, reflect.TypeOf(""))
+	if !ok {
+		return "", false
+	}
+	return v.(string), true
+}
+func (s *tuple) GetInt(i int) (int, bool) {
+	v, ok := s.GetAs(i, reflect.TypeOf(0))
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+func (s *tuple) ToSlice() []interface{} {
+	v := make([]interface{}, len(s.v))
+	copy(v, s.v)
+	return v
+}
+func (s *tuple) Append(vs ...interface{}) Tuple {
+	v := make([]interface{}, len(s.v), len(s.v)+len(vs))
+	copy(v, s.v)
+	return &tuple{v: append(v, vs...)}
+}
+func (s *tuple) Concat(other Tuple) Tuple  { return s.Append(other.ToSlice()...) }
+func (s *tuple) Head() (interface{}, bool) { return s.Get(0) }
+func (s *tuple) Tail() Tuple {
+	if len(s.v) == 0 {
+		return NewTuple()
+	}
+	v := make([]interface{}, len(s.v)-1)
+	copy(v, s.v[1:])
+	return &tuple{v: v}
+}
+func (s *tuple) Map(f Mapper) (Tuple, error) {
+	v := make([]interface{}, len(s.v))
+	for i, x := range s.v {
+		y, err := f.Apply(x)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = y
+	}
+	return &tuple{v: v}, nil
+}
 func (s *tuple) String() string {
 	a := make([]string, len(s.v))
 	for i, x := range s.v {
@@ -199,3 +473,47 @@ func (s *tuple) String() string {
 	}
 	return fmt.Sprintf("Tuple(%s)", strings.Join(a, ","))
 }
+
+// MarshalJSON encodes Tuple as an array of the elements.
+func (s *tuple) MarshalJSON() ([]byte, error) {
+	if s.v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s.v)
+}
+
+// UnmarshalJSON decodes an array into Tuple.
+// The nested arrays are also decoded into Tuple.
+func (s *tuple) UnmarshalJSON(data []byte) error {
+	var xs []interface{}
+	if err := json.Unmarshal(data, &xs); err != nil {
+		return err
+	}
+	if xs == nil {
+		xs = []interface{}{}
+	}
+	s.v = jsonArrayToTuple(xs).v
+	return nil
+}
+
+func jsonArrayToTuple(xs []interface{}) *tuple {
+	v := make([]interface{}, len(xs))
+	for i, x := range xs {
+		if ys, ok := x.([]interface{}); ok {
+			v[i] = jsonArrayToTuple(ys)
+			continue
+		}
+		v[i] = x
+	}
+	return &tuple{v: v}
+}
+
+// UnmarshalTuple decodes the JSON array into Tuple.
+// See tuple.UnmarshalJSON.
+func UnmarshalTuple(data []byte) (Tuple, error) {
+	var t tuple
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -1,7 +1,11 @@
 package circle_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/berquerant/circle"
@@ -52,6 +56,184 @@ func TestMaybeMap(t *testing.T) {
 	}
 }
 
+func TestMaybeFlatMap(t *testing.T) {
+	half := func(x int) (circle.Maybe, error) {
+		if x&1 == 1 {
+			return circle.NewNothing(), nil
+		}
+		return circle.NewJust(x / 2), nil
+	}
+	for _, tc := range []struct {
+		title string
+		arg   circle.Maybe
+		f     interface{}
+		want  circle.Maybe
+	}{
+		{
+			title: "just",
+			arg:   circle.NewJust(4),
+			f:     half,
+			want:  circle.NewJust(2),
+		},
+		{
+			title: "just to nothing",
+			arg:   circle.NewJust(3),
+			f:     half,
+			want:  circle.NewNothing(),
+		},
+		{
+			title: "failure",
+			arg:   circle.NewJust(4),
+			f:     func(int) (circle.Maybe, error) { return nil, errors.New("failure") },
+			want:  circle.NewNothing(),
+		},
+		{
+			title: "not maybe",
+			arg:   circle.NewJust(4),
+			f:     func(x int) int { return x },
+			want:  circle.NewNothing(),
+		},
+		{
+			title: "nothing",
+			arg:   circle.NewNothing(),
+			f:     half,
+			want:  circle.NewNothing(),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := circle.NewMapper(tc.f)
+			assert.Nil(t, err)
+			gotVal, gotOK := tc.arg.FlatMap(f).Get()
+			wantVal, wantOK := tc.want.Get()
+			assert.Equal(t, wantOK, gotOK)
+			assert.Equal(t, wantVal, gotVal)
+		})
+	}
+}
+
+func TestMaybeFold(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		arg     circle.Maybe
+		f       func(int) (string, error)
+		want    interface{}
+		wantErr error
+	}{
+		{
+			title: "just",
+			arg:   circle.NewJust(1),
+			f:     func(x int) (string, error) { return fmt.Sprint(x + 1), nil },
+			want:  "2",
+		},
+		{
+			title:   "failure",
+			arg:     circle.NewJust(1),
+			f:       func(int) (string, error) { return "", errors.New("failure") },
+			want:    "",
+			wantErr: errors.New("failure"),
+		},
+		{
+			title: "nothing",
+			arg:   circle.NewNothing(),
+			f:     func(int) (string, error) { return "", errors.New("failure") },
+			want:  "nothing",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := circle.NewMapper(tc.f)
+			assert.Nil(t, err)
+			got, err := tc.arg.Fold("nothing", f)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMaybeFilterOrError(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		arg     circle.Maybe
+		want    circle.Maybe
+		wantErr error
+	}{
+		{
+			title: "keep",
+			arg:   circle.NewJust(2),
+			want:  circle.NewJust(2),
+		},
+		{
+			title: "exclude",
+			arg:   circle.NewJust(1),
+			want:  circle.NewNothing(),
+		},
+		{
+			title:   "error",
+			arg:     circle.NewJust(-1),
+			want:    circle.NewNothing(),
+			wantErr: errors.New("negative"),
+		},
+		{
+			title: "nothing",
+			arg:   circle.NewNothing(),
+			want:  circle.NewNothing(),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := circle.NewFilter(func(x int) (bool, error) {
+				if x < 0 {
+					return false, errors.New("negative")
+				}
+				return x%2 == 0, nil
+			})
+			assert.Nil(t, err)
+			got, err := tc.arg.FilterOrError(f)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, fmt.Sprint(tc.want), fmt.Sprint(got))
+		})
+	}
+}
+
+func TestMaybeToEither(t *testing.T) {
+	t.Run("just", func(t *testing.T) {
+		got := circle.NewJust(1).ToEither("left")
+		v, ok := got.Right()
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		got := circle.NewNothing().ToEither("left")
+		v, ok := got.Left()
+		assert.True(t, ok)
+		assert.Equal(t, "left", v)
+	})
+}
+
+func TestMaybeOrElseFunc(t *testing.T) {
+	t.Run("just", func(t *testing.T) {
+		var called bool
+		assert.Equal(t, 1, circle.NewJust(1).GetOrElseFunc(func() interface{} {
+			called = true
+			return 2
+		}))
+		got := circle.NewJust(1).OrElseFunc(func() circle.Maybe {
+			called = true
+			return circle.NewJust(2)
+		})
+		assert.Equal(t, 1, got.MustGet())
+		assert.False(t, called)
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		assert.Equal(t, 2, circle.NewNothing().GetOrElseFunc(func() interface{} { return 2 }))
+		got := circle.NewNothing().OrElseFunc(func() circle.Maybe { return circle.NewJust(2) })
+		assert.Equal(t, 2, got.MustGet())
+	})
+}
+
 type (
 	testcaseMaybeFilter struct {
 		title string
@@ -224,6 +406,31 @@ func (s *testcaseEitherConsume) test(t *testing.T) {
 	}
 }
 
+func TestMaybeForEach(t *testing.T) {
+	t.Run("just", func(t *testing.T) {
+		var got int
+		f, err := circle.NewConsumer(func(x int) { got = x })
+		assert.Nil(t, err)
+		assert.Nil(t, circle.NewJust(1).ForEach(f))
+		assert.Equal(t, 1, got)
+	})
+
+	t.Run("just error", func(t *testing.T) {
+		e := errors.New("error")
+		f, err := circle.NewConsumer(func(int) error { return e })
+		assert.Nil(t, err)
+		assert.Equal(t, e, circle.NewJust(1).ForEach(f))
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		var called bool
+		f, err := circle.NewConsumer(func(interface{}) { called = true })
+		assert.Nil(t, err)
+		assert.Nil(t, circle.NewNothing().ForEach(f))
+		assert.False(t, called)
+	})
+}
+
 func TestEitherConsume(t *testing.T) {
 	for _, tc := range []*testcaseEitherConsume{
 		{
@@ -312,6 +519,41 @@ func (s *testcaseEitherMap) test(t *testing.T) {
 	}
 }
 
+func TestEitherForEach(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		var got int
+		f, err := circle.NewConsumer(func(x int) { got = x })
+		assert.Nil(t, err)
+		assert.Nil(t, circle.NewRight(1).ForEach(f))
+		assert.Equal(t, 1, got)
+	})
+
+	t.Run("left", func(t *testing.T) {
+		var called bool
+		f, err := circle.NewConsumer(func(interface{}) { called = true })
+		assert.Nil(t, err)
+		assert.Nil(t, circle.NewLeft(1).ForEach(f))
+		assert.False(t, called)
+	})
+}
+
+func TestEitherToError(t *testing.T) {
+	t.Run("left error", func(t *testing.T) {
+		e := errors.New("failure")
+		assert.Equal(t, e, circle.NewLeft(e).ToError())
+	})
+
+	t.Run("left string", func(t *testing.T) {
+		err := circle.NewLeft("failure").ToError()
+		assert.True(t, errors.Is(err, circle.ErrLeft))
+		assert.Equal(t, "left failure", err.Error())
+	})
+
+	t.Run("right", func(t *testing.T) {
+		assert.Nil(t, circle.NewRight(1).ToError())
+	})
+}
+
 func TestEitherMap(t *testing.T) {
 	for _, tc := range []*testcaseEitherMap{
 		{
@@ -343,6 +585,175 @@ func TestEitherMap(t *testing.T) {
 	}
 }
 
+func TestEitherFlatMap(t *testing.T) {
+	validate := func(x int) (circle.Either, error) {
+		if x < 0 {
+			return circle.NewLeft("negative"), nil
+		}
+		return circle.NewRight(x + 1), nil
+	}
+	for _, tc := range []struct {
+		title string
+		arg   circle.Either
+		f     interface{}
+		want  circle.Either
+	}{
+		{
+			title: "right ok",
+			arg:   circle.NewRight(1),
+			f:     validate,
+			want:  circle.NewRight(2),
+		},
+		{
+			title: "right to left",
+			arg:   circle.NewRight(-1),
+			f:     validate,
+			want:  circle.NewLeft("negative"),
+		},
+		{
+			title: "right failure",
+			arg:   circle.NewRight(1),
+			f:     func(int) (circle.Either, error) { return nil, errors.New("failure") },
+			want:  circle.NewLeft(errors.New("failure")),
+		},
+		{
+			title: "right not either",
+			arg:   circle.NewRight(1),
+			f:     func(x int) int { return x },
+			want:  circle.NewLeft(errors.New("not either")),
+		},
+		{
+			title: "left",
+			arg:   circle.NewLeft(10),
+			f:     validate,
+			want:  circle.NewLeft(10),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			f, err := circle.NewMapper(tc.f)
+			assert.Nil(t, err)
+			got := tc.arg.FlatMap(f)
+			assert.Equal(t, fmt.Sprint(tc.want), fmt.Sprint(got))
+		})
+	}
+}
+
+func TestEitherFold(t *testing.T) {
+	f, err := circle.NewMapper(func(err error) string { return fmt.Sprintf("left(%v)", err) })
+	assert.Nil(t, err)
+	g, err := circle.NewMapper(func(x int) (string, error) {
+		if x < 0 {
+			return "", errors.New("negative")
+		}
+		return fmt.Sprintf("right(%d)", x), nil
+	})
+	assert.Nil(t, err)
+	for _, tc := range []struct {
+		title   string
+		arg     circle.Either
+		want    interface{}
+		wantErr error
+	}{
+		{
+			title: "right",
+			arg:   circle.NewRight(1),
+			want:  "right(1)",
+		},
+		{
+			title:   "right failure",
+			arg:     circle.NewRight(-1),
+			want:    "",
+			wantErr: errors.New("negative"),
+		},
+		{
+			title: "left",
+			arg:   circle.NewLeft(errors.New("left")),
+			want:  "left(left)",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := tc.arg.Fold(f, g)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+type (
+	testcaseEitherSwap struct {
+		title string
+		arg   circle.Either
+		want  circle.Either
+	}
+)
+
+func (s *testcaseEitherSwap) test(t *testing.T) {
+	got := s.arg.Swap()
+	{
+		gotVal, gotOK := got.Right()
+		wantVal, wantOK := s.want.Right()
+		assert.Equal(t, gotOK, wantOK)
+		assert.Equal(t, gotVal, wantVal)
+	}
+	{
+		gotVal, gotOK := got.Left()
+		wantVal, wantOK := s.want.Left()
+		assert.Equal(t, gotOK, wantOK)
+		assert.Equal(t, gotVal, wantVal)
+	}
+}
+
+func TestEitherSwap(t *testing.T) {
+	for _, tc := range []*testcaseEitherSwap{
+		{
+			title: "right",
+			arg:   circle.NewRight(1),
+			want:  circle.NewLeft(1),
+		},
+		{
+			title: "left",
+			arg:   circle.NewLeft(errors.New("left")),
+			want:  circle.NewRight(errors.New("left")),
+		},
+	} {
+		t.Run(tc.title, tc.test)
+	}
+}
+
+func TestEitherGetOrElseFunc(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		var called bool
+		assert.Equal(t, 1, circle.NewRight(1).GetOrElseFunc(func() interface{} {
+			called = true
+			return 2
+		}))
+		assert.False(t, called)
+	})
+
+	t.Run("left", func(t *testing.T) {
+		assert.Equal(t, 2, circle.NewLeft(1).GetOrElseFunc(func() interface{} { return 2 }))
+	})
+}
+
+func TestFromError(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		got := circle.FromError(1, nil)
+		v, ok := got.Right()
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("left", func(t *testing.T) {
+		e := errors.New("left")
+		got := circle.FromError(1, e)
+		v, ok := got.Left()
+		assert.True(t, ok)
+		assert.Equal(t, e, v)
+	})
+}
+
 func TestTuple(t *testing.T) {
 	t.Run("zero", func(t *testing.T) {
 		v := circle.NewTuple()
@@ -351,6 +762,85 @@ func TestTuple(t *testing.T) {
 		assert.False(t, ok)
 	})
 
+	t.Run("to slice", func(t *testing.T) {
+		v := circle.NewTuple(1, "two")
+		xs := v.ToSlice()
+		assert.Equal(t, []interface{}{1, "two"}, xs)
+		xs[0] = 10
+		assert.Equal(t, 1, v.MustGet(0), "should be a copy")
+		assert.Equal(t, []interface{}{}, circle.NewTuple().ToSlice())
+	})
+
+	t.Run("append", func(t *testing.T) {
+		v := circle.NewTuple(1)
+		w := v.Append("two", 3.0)
+		assert.Equal(t, 1, v.Size(), "should not mutate")
+		assert.Equal(t, 3, w.Size())
+		assert.Equal(t, []interface{}{1, "two", 3.0}, w.ToSlice())
+		assert.Equal(t, []interface{}{1}, v.Append().ToSlice())
+		assert.Equal(t, []interface{}{1}, circle.NewTuple().Append(1).ToSlice())
+	})
+
+	t.Run("concat", func(t *testing.T) {
+		v := circle.NewTuple(1, 2)
+		w := v.Concat(circle.NewTuple(3))
+		assert.Equal(t, 2, v.Size(), "should not mutate")
+		assert.Equal(t, 3, w.Size())
+		assert.Equal(t, []interface{}{1, 2, 3}, w.ToSlice())
+		assert.Equal(t, []interface{}{1, 2}, v.Concat(circle.NewTuple()).ToSlice())
+		assert.Equal(t, 0, circle.NewTuple().Concat(circle.NewTuple()).Size())
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		type myString string
+		v := circle.NewTuple(1, "two", 3.5, myString("four"), nil)
+		{
+			x, ok := v.GetInt(0)
+			assert.True(t, ok)
+			assert.Equal(t, 1, x)
+		}
+		{
+			x, ok := v.GetInt(2)
+			assert.True(t, ok)
+			assert.Equal(t, 3, x)
+		}
+		{
+			_, ok := v.GetInt(1)
+			assert.False(t, ok)
+		}
+		{
+			_, ok := v.GetInt(4)
+			assert.False(t, ok)
+		}
+		{
+			_, ok := v.GetInt(5)
+			assert.False(t, ok)
+		}
+		{
+			x, ok := v.GetString(1)
+			assert.True(t, ok)
+			assert.Equal(t, "two", x)
+		}
+		{
+			x, ok := v.GetString(3)
+			assert.True(t, ok)
+			assert.Equal(t, "four", x)
+		}
+		{
+			_, ok := v.GetString(0)
+			assert.False(t, ok)
+		}
+		{
+			x, ok := v.GetAs(0, reflect.TypeOf(float64(0)))
+			assert.True(t, ok)
+			assert.Equal(t, float64(1), x)
+		}
+		{
+			_, ok := v.GetAs(-1, reflect.TypeOf(float64(0)))
+			assert.False(t, ok)
+		}
+	})
+
 	t.Run("double", func(t *testing.T) {
 		v := circle.NewTuple(1, "two")
 		assert.Equal(t, 2, v.Size())
@@ -370,3 +860,241 @@ func TestTuple(t *testing.T) {
 		}
 	})
 }
+
+func TestMaybeJSON(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		arg   circle.Maybe
+		want  string
+	}{
+		{
+			title: "just",
+			arg:   circle.NewJust("value"),
+			want:  `"value"`,
+		},
+		{
+			title: "just object",
+			arg:   circle.NewJust(map[string]interface{}{"k": 1.5}),
+			want:  `{"k":1.5}`,
+		},
+		{
+			title: "nothing",
+			arg:   circle.NewNothing(),
+			want:  `null`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			b, err := json.Marshal(tc.arg)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(b))
+			got, err := circle.UnmarshalMaybe(b)
+			assert.Nil(t, err)
+			assert.Equal(t, fmt.Sprint(tc.arg), fmt.Sprint(got))
+		})
+	}
+
+	t.Run("field", func(t *testing.T) {
+		b, err := json.Marshal(struct {
+			V circle.Maybe `json:"v"`
+		}{
+			V: circle.NewJust(1),
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, `{"v":1}`, string(b))
+	})
+}
+
+func TestEitherJSON(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		arg      circle.Either
+		want     string
+		wantBack circle.Either
+	}{
+		{
+			title:    "right",
+			arg:      circle.NewRight("value"),
+			want:     `{"right":"value"}`,
+			wantBack: circle.NewRight("value"),
+		},
+		{
+			title:    "left",
+			arg:      circle.NewLeft(1.0),
+			want:     `{"left":1}`,
+			wantBack: circle.NewLeft(1.0),
+		},
+		{
+			title:    "left error",
+			arg:      circle.NewLeft(errors.New("failure")),
+			want:     `{"left":"failure"}`,
+			wantBack: circle.NewLeft("failure"),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			b, err := json.Marshal(tc.arg)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(b))
+			got, err := circle.UnmarshalEither(b)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantBack.IsLeft(), got.IsLeft())
+			assert.Equal(t, fmt.Sprint(tc.wantBack), fmt.Sprint(got))
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, data := range []string{`{}`, `{"left":1,"right":2}`, `{"center":1}`} {
+			_, err := circle.UnmarshalEither([]byte(data))
+			assert.Equal(t, circle.ErrInvalidEitherJSON, err, data)
+		}
+		_, err := circle.UnmarshalEither([]byte(`[`))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTupleHeadTail(t *testing.T) {
+	for _, tc := range []struct {
+		title    string
+		arg      circle.Tuple
+		wantHead interface{}
+		wantOK   bool
+		wantTail []interface{}
+	}{
+		{
+			title:    "empty",
+			arg:      circle.NewTuple(),
+			wantTail: []interface{}{},
+		},
+		{
+			title:    "single",
+			arg:      circle.NewTuple(1),
+			wantHead: 1,
+			wantOK:   true,
+			wantTail: []interface{}{},
+		},
+		{
+			title:    "triple",
+			arg:      circle.NewTuple(1, "two", 3.0),
+			wantHead: 1,
+			wantOK:   true,
+			wantTail: []interface{}{"two", 3.0},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			head, ok := tc.arg.Head()
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantHead, head)
+			tail := tc.arg.Tail()
+			assert.Equal(t, tc.wantTail, tail.ToSlice())
+			assert.Equal(t, len(tc.wantTail), tail.Size())
+		})
+	}
+}
+
+func TestTupleMap(t *testing.T) {
+	trim, err := circle.NewMapper(func(x interface{}) interface{} {
+		if s, ok := x.(string); ok {
+			return strings.TrimSpace(s)
+		}
+		return x
+	})
+	assert.Nil(t, err)
+
+	t.Run("empty", func(t *testing.T) {
+		got, err := circle.NewTuple().Map(trim)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, got.Size())
+	})
+
+	t.Run("map", func(t *testing.T) {
+		src := circle.NewTuple(" a ", 1, "b ")
+		got, err := src.Map(trim)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"a", 1, "b"}, got.ToSlice())
+		assert.Equal(t, []interface{}{" a ", 1, "b "}, src.ToSlice())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("not int")
+		f, err := circle.NewMapper(func(x interface{}) (int, error) {
+			if v, ok := x.(int); ok {
+				return v * 2, nil
+			}
+			return 0, e
+		})
+		assert.Nil(t, err)
+		_, err = circle.NewTuple(1, "2").Map(f)
+		assert.Equal(t, e, err)
+	})
+}
+
+func TestTupleJSON(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		arg   circle.Tuple
+		want  string
+	}{
+		{
+			title: "empty",
+			arg:   circle.NewTuple(),
+			want:  `[]`,
+		},
+		{
+			title: "flat",
+			arg:   circle.NewTuple("key", 1.5, true),
+			want:  `["key",1.5,true]`,
+		},
+		{
+			title: "nested",
+			arg:   circle.NewTuple("a", circle.NewTuple("b", circle.NewTuple(1.0))),
+			want:  `["a",["b",[1]]]`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			b, err := json.Marshal(tc.arg)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(b))
+			got, err := circle.UnmarshalTuple(b)
+			assert.Nil(t, err)
+			assert.Equal(t, fmt.Sprint(tc.arg), fmt.Sprint(got))
+		})
+	}
+
+	t.Run("map iterator", func(t *testing.T) {
+		xs, err := circle.Collect(circle.MustNewIterator(map[string]int{"k": 1}))
+		assert.Nil(t, err)
+		b, err := json.Marshal(xs)
+		assert.Nil(t, err)
+		assert.Equal(t, `[["k",1]]`, string(b))
+	})
+
+	t.Run("not array", func(t *testing.T) {
+		_, err := circle.UnmarshalTuple([]byte(`{"k":1}`))
+		assert.NotNil(t, err)
+	})
+}
+
+func BenchmarkTupleGetInt(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "exact", v: 1},
+		{name: "convert", v: int64(1)},
+	} {
+		bc := bc
+		b.Run(bc.name, func(b *testing.B) {
+			x := circle.NewTuple(bc.v)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := x.GetInt(0); !ok {
+					b.Fatal("cannot get int")
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package circle
 
 import (
+	"container/heap"
 	"errors"
+	"fmt"
+	"reflect"
 	"sort"
+	"sync"
 )
 
 type (
@@ -16,264 +20,1868 @@ type (
 
 	executorOption struct {
 		aggregateExecutorOption
+		parallelMapExecutorOption
+		compareExecutorOption
+		errorCollectorExecutorOption
+		flatExecutorOption
+		bufferExecutorOption
+	}
+
+	errorCollectorExecutorOption struct {
+		errorCollector   func(error)
+		isResumedOnError bool
+	}
+
+	bufferExecutorOption struct {
+		maxBuffer int
+	}
+)
+
+var (
+	ErrBufferOverflow = errors.New("buffer overflow")
+)
+
+// WithExecutorMaxBuffer makes the Executor for sort or reverse yield ErrBufferOverflow
+// when it receives more than n elements to buffer.
+// If n is not positive, the buffer is unlimited.
+func WithExecutorMaxBuffer(n int) ExecutorOption {
+	return func(ex Executor) {
+		switch x := ex.(type) {
+		case *compareExecutor:
+			x.opt.maxBuffer = n
+		case *reverseExecutor:
+			x.opt.maxBuffer = n
+		}
+	}
+}
+
+// isOverflow returns true if the buffer of size exceeds the max buffer.
+func (s *bufferExecutorOption) isOverflow(size int) bool {
+	return s.maxBuffer > 0 && size > s.maxBuffer
+}
+
+// WithExecutorErrorCollector makes the Executor for map or filter pass the error from the function to f
+// and continue the iteration, instead of ignoring or stopping on the error.
+func WithExecutorErrorCollector(f func(error)) ExecutorOption {
+	return func(ex Executor) {
+		switch x := ex.(type) {
+		case *mapExecutor:
+			x.opt.errorCollector = f
+		case *filterExecutor:
+			x.opt.errorCollector = f
+		}
+	}
+}
+
+// WithExecutorResumeOnError makes the Executor for filter drop the element that the function returns error for
+// and continue the iteration, instead of stopping on the error.
+func WithExecutorResumeOnError(isResumed bool) ExecutorOption {
+	return func(ex Executor) {
+		if x, ok := ex.(*filterExecutor); ok {
+			x.opt.isResumedOnError = isResumed
+		}
+	}
+}
+
+type (
+	mapExecutor struct {
+		f   Mapper
+		it  Iterator
+		opt *executorOption
+	}
+)
+
+// NewMapExecutor returns a new Executor for map.
+//
+// If f returns error, the argument of f is ignored, this does not yield it.
+// See WithExecutorErrorCollector().
+func NewMapExecutor(f Mapper, it Iterator, opt ...ExecutorOption) Executor {
+	ex := &mapExecutor{
+		f:   f,
+		it:  it,
+		opt: &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+	return ex
+}
+
+func (s *mapExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.f.Apply(x)
+			if err != nil {
+				if s.opt.errorCollector != nil {
+					s.opt.errorCollector(err)
+				}
+				// ignore this value
+				continue
+			}
+			return v, nil
+		}
+	})
+}
+
+var (
+	ErrInvalidWorkers = errors.New("invalid workers")
+)
+
+type (
+	parallelMapExecutor struct {
+		f       Mapper
+		it      Iterator
+		workers int
+		opt     *executorOption
+	}
+
+	parallelMapExecutorOption struct {
+		isUnordered bool
+	}
+
+	parallelMapResult struct {
+		v   interface{}
+		err error
+	}
+)
+
+// NewParallelMapExecutor returns a new Executor for map that applies f concurrently.
+//
+// This applies f to at most workers elements at the same time.
+// The results are yielded in the order of the input by default,
+// see WithParallelMapExecutorOrder().
+// If f returns error, the argument of f is ignored, this does not yield it.
+// If workers is not positive, returns ErrInvalidWorkers.
+//
+// Elements are pulled from it only when the iterator is iterated,
+// so no goroutines remain after the iteration is abandoned except ones running f.
+func NewParallelMapExecutor(f Mapper, it Iterator, workers int, opt ...ExecutorOption) (Executor, error) {
+	if workers <= 0 {
+		return nil, ErrInvalidWorkers
+	}
+	ex := &parallelMapExecutor{
+		f:       f,
+		it:      it,
+		workers: workers,
+		opt:     &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+	return ex, nil
+}
+
+// WithParallelMapExecutorOrder sets whether the Executor for parallel map preserves the order of the input.
+// If isOrdered is false, the results are yielded as soon as they are available.
+func WithParallelMapExecutorOrder(isOrdered bool) ExecutorOption {
+	return func(ex Executor) {
+		if px, ok := ex.(*parallelMapExecutor); ok {
+			px.opt.isUnordered = !isOrdered
+		}
+	}
+}
+
+func (s *parallelMapExecutor) apply(x interface{}, c chan<- *parallelMapResult) {
+	go func() {
+		v, err := s.f.Apply(x)
+		c <- &parallelMapResult{
+			v:   v,
+			err: err,
+		}
+	}()
+}
+
+func (s *parallelMapExecutor) Execute() (Iterator, error) {
+	if s.opt.isUnordered {
+		return s.executeUnordered()
+	}
+	return s.executeOrdered()
+}
+
+func (s *parallelMapExecutor) executeOrdered() (Iterator, error) {
+	var (
+		queue  []chan *parallelMapResult
+		srcErr error
+	)
+	return NewIterator(func() (interface{}, error) {
+		for {
+			for srcErr == nil && len(queue) < s.workers {
+				x, err := s.it.Next()
+				if err != nil {
+					srcErr = err
+					break
+				}
+				c := make(chan *parallelMapResult, 1)
+				s.apply(x, c)
+				queue = append(queue, c)
+			}
+			if len(queue) == 0 {
+				return nil, srcErr
+			}
+			r := <-queue[0]
+			queue = queue[1:]
+			if r.err != nil {
+				// ignore this value
+				continue
+			}
+			return r.v, nil
+		}
+	})
+}
+
+func (s *parallelMapExecutor) executeUnordered() (Iterator, error) {
+	var (
+		c        = make(chan *parallelMapResult, s.workers)
+		inFlight int
+		srcErr   error
+	)
+	return NewIterator(func() (interface{}, error) {
+		for {
+			for srcErr == nil && inFlight < s.workers {
+				x, err := s.it.Next()
+				if err != nil {
+					srcErr = err
+					break
+				}
+				s.apply(x, c)
+				inFlight++
+			}
+			if inFlight == 0 {
+				return nil, srcErr
+			}
+			r := <-c
+			inFlight--
+			if r.err != nil {
+				// ignore this value
+				continue
+			}
+			return r.v, nil
+		}
+	})
+}
+
+type (
+	filterExecutor struct {
+		f   Filter
+		it  Iterator
+		opt *executorOption
+	}
+)
+
+// NewFilterExecutor returns a new Executor for filter.
+//
+// If f returns error, the iterator ends here.
+// If WithExecutorErrorCollector() or WithExecutorResumeOnError(true) is given,
+// the argument of f is ignored and the iteration continues instead.
+func NewFilterExecutor(f Filter, it Iterator, opt ...ExecutorOption) Executor {
+	ex := &filterExecutor{
+		f:   f,
+		it:  it,
+		opt: &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+	return ex
+}
+
+func (s *filterExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.f.Apply(x)
+			if err != nil && s.opt.errorCollector != nil {
+				s.opt.errorCollector(err)
+				// skip
+				continue
+			}
+			if err != nil && s.opt.isResumedOnError {
+				// skip
+				continue
+			}
+			if err != nil {
+				// ends iterator
+				return nil, err
+			}
+			if !v {
+				// skip
+				continue
+			}
+			return x, nil
+		}
+	})
+}
+
+type (
+	takeWhileExecutor struct {
+		f  Filter
+		it Iterator
+	}
+)
+
+// NewTakeWhileExecutor returns a new Executor for take while.
+//
+// This yields elements while f returns true.
+// If f returns false, the iterator ends here without consuming further elements.
+// If f returns error, the iterator ends here.
+func NewTakeWhileExecutor(f Filter, it Iterator) Executor {
+	return &takeWhileExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *takeWhileExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		x, err := s.it.Next()
+		if err != nil {
+			return nil, err
+		}
+		v, err := s.f.Apply(x)
+		if err != nil {
+			// ends iterator
+			return nil, err
+		}
+		if !v {
+			return nil, ErrEOI
+		}
+		return x, nil
+	})
+}
+
+type (
+	dropWhileExecutor struct {
+		f  Filter
+		it Iterator
+	}
+)
+
+// NewDropWhileExecutor returns a new Executor for drop while.
+//
+// This discards elements while f returns true.
+// Once f returns false, yields the element and all subsequent elements without calling f.
+// If f returns error while discarding, the iterator ends here.
+func NewDropWhileExecutor(f Filter, it Iterator) Executor {
+	return &dropWhileExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *dropWhileExecutor) Execute() (Iterator, error) {
+	var isDropped bool
+	return NewIterator(func() (interface{}, error) {
+		if isDropped {
+			return s.it.Next()
+		}
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			v, err := s.f.Apply(x)
+			if err != nil {
+				// ends iterator
+				return nil, err
+			}
+			if !v {
+				isDropped = true
+				return x, nil
+			}
+		}
+	})
+}
+
+var (
+	ErrNotHashable = errors.New("not hashable")
+)
+
+type (
+	// hashSet is a set of hashable values.
+	hashSet map[interface{}]struct{}
+)
+
+// add adds x to this and returns true if x is a new element.
+// If x is not hashable, returns ErrNotHashable.
+func (s hashSet) add(x interface{}) (isNew bool, rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			isNew = false
+			rerr = fmt.Errorf("%w %T", ErrNotHashable, x)
+		}
+	}()
+	if _, ok := s[x]; ok {
+		return false, nil
+	}
+	s[x] = struct{}{}
+	return true, nil
+}
+
+type (
+	distinctExecutor struct {
+		it Iterator
+	}
+)
+
+// NewDistinctExecutor returns a new Executor for distinct.
+//
+// This yields only the first occurrence of each element, the order of them is preserved.
+// The elements are compared as keys of a map,
+// if an element is not hashable such as a slice or a map, the iterator ends here with ErrNotHashable.
+func NewDistinctExecutor(it Iterator) Executor {
+	return &distinctExecutor{
+		it: it,
+	}
+}
+
+func (s *distinctExecutor) Execute() (Iterator, error) {
+	seen := hashSet{}
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			isNew, err := seen.add(x)
+			if err != nil {
+				return nil, err
+			}
+			if isNew {
+				return x, nil
+			}
+		}
+	})
+}
+
+type (
+	distinctWindowExecutor struct {
+		n  int
+		it Iterator
+	}
+)
+
+// NewDistinctWindowExecutor returns a new Executor for distinct within a window.
+//
+// This remembers only the last n distinct elements yielded, and drops an element if it equals one of them.
+// The oldest element falls out of the window when a new element is yielded,
+// then the element can be yielded again, so this is an approximation of distinct with bounded memory.
+// A dropped element does not refresh its position in the window.
+// If an element is not hashable such as a slice or a map, the iterator ends here with ErrNotHashable.
+// If n is not positive, returns ErrInvalidSize.
+func NewDistinctWindowExecutor(n int, it Iterator) (Executor, error) {
+	if n <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &distinctWindowExecutor{
+		n:  n,
+		it: it,
+	}, nil
+}
+
+func (s *distinctWindowExecutor) Execute() (Iterator, error) {
+	var (
+		seen = hashSet{}
+		// ring buffer of the elements in seen, buf[head] is the oldest if full
+		buf  = make([]interface{}, s.n)
+		head int
+		size int
+	)
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			isNew, err := seen.add(x)
+			if err != nil {
+				return nil, err
+			}
+			if !isNew {
+				continue
+			}
+			if size < s.n {
+				buf[(head+size)%s.n] = x
+				size++
+				return x, nil
+			}
+			// evict the oldest
+			delete(seen, buf[head])
+			buf[head] = x
+			head = (head + 1) % s.n
+			return x, nil
+		}
+	})
+}
+
+type (
+	distinctByExecutor struct {
+		f  Mapper
+		it Iterator
+	}
+)
+
+// NewDistinctByExecutor returns a new Executor for distinct by key.
+//
+// This yields only the first element of each key extracted by f, the order of them is preserved.
+// If f returns error or the key is not hashable, the iterator ends here.
+func NewDistinctByExecutor(f Mapper, it Iterator) Executor {
+	return &distinctByExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *distinctByExecutor) Execute() (Iterator, error) {
+	seen := hashSet{}
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			k, err := s.f.Apply(x)
+			if err != nil {
+				// ends iterator
+				return nil, err
+			}
+			isNew, err := seen.add(k)
+			if err != nil {
+				return nil, err
+			}
+			if isNew {
+				return x, nil
+			}
+		}
+	})
+}
+
+// isEqual returns true if x equals y.
+// If x and y are not comparable, returns ErrNotHashable.
+func isEqual(x, y interface{}) (ok bool, rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			ok = false
+			rerr = fmt.Errorf("%w %T", ErrNotHashable, x)
+		}
+	}()
+	return x == y, nil
+}
+
+type (
+	dedupExecutor struct {
+		f  Mapper
+		it Iterator
+	}
+)
+
+// NewDedupExecutor returns a new Executor for dedup.
+//
+// This drops an element if it equals the previous element, so it collapses runs of consecutive duplicates.
+// Unlike distinct, this holds only the previous element.
+// If an element is not comparable such as a slice or a map, the iterator ends here with ErrNotHashable.
+func NewDedupExecutor(it Iterator) Executor {
+	return &dedupExecutor{
+		it: it,
+	}
+}
+
+// NewDedupByExecutor returns a new Executor for dedup by key.
+//
+// This drops an element if its key extracted by f equals the key of the previous element.
+// If f returns error or the key is not comparable, the iterator ends here.
+func NewDedupByExecutor(f Mapper, it Iterator) Executor {
+	return &dedupExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *dedupExecutor) key(x interface{}) (interface{}, error) {
+	if s.f == nil {
+		return x, nil
+	}
+	return s.f.Apply(x)
+}
+
+func (s *dedupExecutor) Execute() (Iterator, error) {
+	var (
+		prev    interface{}
+		hasPrev bool
+	)
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			k, err := s.key(x)
+			if err != nil {
+				// ends iterator
+				return nil, err
+			}
+			if hasPrev {
+				isDup, err := isEqual(prev, k)
+				if err != nil {
+					return nil, err
+				}
+				if isDup {
+					continue
+				}
+			}
+			prev = k
+			hasPrev = true
+			return x, nil
+		}
+	})
+}
+
+type (
+	intersperseExecutor struct {
+		sep interface{}
+		it  Iterator
+	}
+)
+
+// NewIntersperseExecutor returns a new Executor for intersperse.
+//
+// This yields sep between successive elements of it, not before the first or after the last.
+func NewIntersperseExecutor(sep interface{}, it Iterator) Executor {
+	return &intersperseExecutor{
+		sep: sep,
+		it:  it,
+	}
+}
+
+func (s *intersperseExecutor) Execute() (Iterator, error) {
+	var (
+		isStarted bool
+		next      interface{}
+		hasNext   bool
+	)
+	return NewIterator(func() (interface{}, error) {
+		if hasNext {
+			hasNext = false
+			return next, nil
+		}
+		x, err := s.it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !isStarted {
+			isStarted = true
+			return x, nil
+		}
+		// yield the element after sep
+		next = x
+		hasNext = true
+		return s.sep, nil
+	})
+}
+
+type (
+	peekExecutor struct {
+		f  Consumer
+		it Iterator
+	}
+)
+
+// NewPeekExecutor returns a new Executor for peek.
+//
+// This passes each element to f and yields the element unchanged.
+// If f returns error, the iterator ends here.
+func NewPeekExecutor(f Consumer, it Iterator) Executor {
+	return &peekExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *peekExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		x, err := s.it.Next()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.f.Apply(x); err != nil {
+			// ends iterator
+			return nil, err
+		}
+		return x, nil
+	})
+}
+
+type (
+	mapErrorExecutor struct {
+		f  func(error) error
+		it Iterator
+	}
+)
+
+// NewMapErrorExecutor returns a new Executor for map error.
+//
+// This yields the elements of it unchanged,
+// and converts the error that ends it except ErrEOI by f.
+// If f returns nil, the iterator ends normally.
+func NewMapErrorExecutor(f func(error) error, it Iterator) Executor {
+	return &mapErrorExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *mapErrorExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			return nil, ErrEOI
+		}
+		if err != nil {
+			if err := s.f(err); err != nil {
+				return nil, err
+			}
+			return nil, ErrEOI
+		}
+		return x, nil
+	})
+}
+
+type (
+	recoverExecutor struct {
+		f  Mapper
+		it Iterator
+	}
+)
+
+// NewRecoverExecutor returns a new Executor for recover.
+//
+// This yields the elements of it unchanged.
+// If it ends with error except ErrEOI, passes the error to f, func(error) (A, error) or func(error) A,
+// and yields the result of f as the last element.
+// If f returns error, the iterator ends with the error.
+// f is called at most once.
+func NewRecoverExecutor(f Mapper, it Iterator) Executor {
+	return &recoverExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *recoverExecutor) Execute() (Iterator, error) {
+	var isRecovered bool
+	return NewIterator(func() (interface{}, error) {
+		if isRecovered {
+			return nil, ErrEOI
+		}
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			return nil, ErrEOI
+		}
+		if err != nil {
+			isRecovered = true
+			return s.f.Apply(err)
+		}
+		return x, nil
+	})
+}
+
+type (
+	groupByExecutor struct {
+		f  Mapper
+		it Iterator
+	}
+
+	// groups is a collection of groups that remembers the insertion order of keys.
+	groups struct {
+		keys   []interface{}
+		values map[interface{}][]interface{}
+	}
+)
+
+func newGroups() *groups {
+	return &groups{
+		keys:   []interface{}{},
+		values: map[interface{}][]interface{}{},
+	}
+}
+
+// add appends v to the group of k.
+// If k is not hashable, returns ErrNotHashable.
+func (s *groups) add(k, v interface{}) (rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			rerr = fmt.Errorf("%w %T", ErrNotHashable, k)
+		}
+	}()
+	if _, ok := s.values[k]; !ok {
+		s.keys = append(s.keys, k)
+	}
+	s.values[k] = append(s.values[k], v)
+	return nil
+}
+
+// NewGroupByExecutor returns a new Executor for group by.
+//
+// This consumes all elements and yields Tuple(key, []value) for each key extracted by f,
+// in the order of the first occurrence of keys.
+// If f returns error, the argument of f is ignored.
+// If it yields error or a key is not hashable, the iterator ends here.
+func NewGroupByExecutor(f Mapper, it Iterator) Executor {
+	return &groupByExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *groupByExecutor) group() (*groups, error) {
+	g := newGroups()
+	for {
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			return g, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		k, err := s.f.Apply(x)
+		if err != nil {
+			// ignore this value
+			continue
+		}
+		if err := g.add(k, x); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *groupByExecutor) Execute() (Iterator, error) {
+	var (
+		g *groups
+		i int
+	)
+	return NewIterator(func() (interface{}, error) {
+		if g == nil {
+			var err error
+			if g, err = s.group(); err != nil {
+				return nil, err
+			}
+		}
+		if i >= len(g.keys) {
+			return nil, ErrEOI
+		}
+		k := g.keys[i]
+		i++
+		return NewTuple(k, g.values[k]), nil
+	})
+}
+
+type (
+	// PartitionExecutor provides an interface for splitting iterator into two iterators.
+	PartitionExecutor interface {
+		PartitionExecute() (Iterator, Iterator, error)
+	}
+
+	partitionExecutor struct {
+		f  Filter
+		it Iterator
+	}
+)
+
+// NewPartitionExecutor returns a new PartitionExecutor.
+//
+// This splits it into the iterator of the elements that f returns true
+// and the iterator of the elements that f returns false.
+// All elements of it are buffered when either iterator is iterated first.
+// If it yields error or f returns error, both iterators end with the error
+// after yielding the elements buffered before the error.
+func NewPartitionExecutor(f Filter, it Iterator) PartitionExecutor {
+	return &partitionExecutor{
+		f:  f,
+		it: it,
+	}
+}
+
+func (s *partitionExecutor) PartitionExecute() (Iterator, Iterator, error) {
+	var (
+		once           sync.Once
+		err            error
+		matched, other []interface{}
+	)
+	partition := func() {
+		for {
+			x, e := s.it.Next()
+			if e == ErrEOI {
+				return
+			}
+			if e != nil {
+				err = e
+				return
+			}
+			v, e := s.f.Apply(x)
+			if e != nil {
+				err = e
+				return
+			}
+			if v {
+				matched = append(matched, x)
+			} else {
+				other = append(other, x)
+			}
+		}
+	}
+	side := func(xs *[]interface{}) IteratorFunc {
+		var i int
+		return func() (interface{}, error) {
+			once.Do(partition)
+			if i < len(*xs) {
+				i++
+				return (*xs)[i-1], nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrEOI
+		}
+	}
+	return newIterator(side(&matched)), newIterator(side(&other)), nil
+}
+
+var (
+	ErrInvalidSize = errors.New("invalid size")
+)
+
+type (
+	chunkExecutor struct {
+		size int
+		it   Iterator
+	}
+)
+
+// NewChunkExecutor returns a new Executor for chunk.
+//
+// This yields []interface{} that contains size consecutive elements,
+// the last chunk may be shorter than size.
+// If size is not positive, returns ErrInvalidSize.
+func NewChunkExecutor(size int, it Iterator) (Executor, error) {
+	if size <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &chunkExecutor{
+		size: size,
+		it:   it,
+	}, nil
+}
+
+func (s *chunkExecutor) Execute() (Iterator, error) {
+	return NewIterator(func() (interface{}, error) {
+		xs := make([]interface{}, 0, s.size)
+		for len(xs) < s.size {
+			x, err := s.it.Next()
+			if err == ErrEOI && len(xs) > 0 {
+				// flush the remainder
+				return xs, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			xs = append(xs, x)
+		}
+		return xs, nil
+	})
+}
+
+type (
+	stepExecutor struct {
+		n  int
+		it Iterator
+	}
+)
+
+// NewStepExecutor returns a new Executor for step.
+//
+// This yields every n-th element, the 0th, n-th, 2n-th, ..., and discards the rest.
+// If n is not positive, returns ErrInvalidSize.
+func NewStepExecutor(n int, it Iterator) (Executor, error) {
+	if n <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &stepExecutor{
+		n:  n,
+		it: it,
+	}, nil
+}
+
+func (s *stepExecutor) Execute() (Iterator, error) {
+	var i int
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			isTarget := i == 0
+			i = (i + 1) % s.n
+			if isTarget {
+				return x, nil
+			}
+		}
+	})
+}
+
+type (
+	windowExecutor struct {
+		size int
+		it   Iterator
+	}
+)
+
+// NewWindowExecutor returns a new Executor for sliding window.
+//
+// This yields Tuple that contains size consecutive elements, advancing by one element.
+// If it yields fewer than size elements, this yields nothing.
+// If size is not positive, returns ErrInvalidSize.
+func NewWindowExecutor(size int, it Iterator) (Executor, error) {
+	if size <= 0 {
+		return nil, ErrInvalidSize
+	}
+	return &windowExecutor{
+		size: size,
+		it:   it,
+	}, nil
+}
+
+func (s *windowExecutor) Execute() (Iterator, error) {
+	var (
+		buf  = make([]interface{}, s.size) // ring buffer
+		head int
+		n    int
+	)
+	return NewIterator(func() (interface{}, error) {
+		for {
+			x, err := s.it.Next()
+			if err != nil {
+				return nil, err
+			}
+			buf[head] = x
+			head = (head + 1) % s.size
+			if n < s.size {
+				n++
+			}
+			if n == s.size {
+				v := make([]interface{}, s.size)
+				for i := 0; i < s.size; i++ {
+					v[i] = buf[(head+i)%s.size]
+				}
+				return NewTuple(v...), nil
+			}
+		}
+	})
+}
+
+var (
+	ErrInvalidAggregateExecutor = errors.New("invalid aggregate executor")
+)
+
+type (
+	aggregateExecutor struct {
+		f    Aggregator
+		it   Iterator
+		iv   interface{}
+		cond Filter
+		opt  *executorOption
+	}
+
+	aggregateExecutorOption struct {
+		aggregateExecutorType AggregateExecutorType
+	}
+
+	// AgregateExecutorType is a type of aggregation.
+	AggregateExecutorType int
+)
+
+const (
+	UnknownAggregateExecutorType AggregateExecutorType = iota
+	// RAggregateExecutorType is foldr.
+	RAggregateExecutorType
+	// LAggregateExecutorType is foldl.
+	LAggregateExecutorType
+)
+
+// NewAggregateExecutor returns a new Executor for aggregate.
+//
+// If f is PerfectAggregatorType, aggregates by foldl unless WithAggregateExecutorType() is given.
+// If f is not appropriate for aggregate, returns ErrInvalidAggregateExecutor.
+func NewAggregateExecutor(f Aggregator, it Iterator, iv interface{}, opt ...ExecutorOption) (Executor, error) {
+	ex := &aggregateExecutor{
+		f:   f,
+		it:  it,
+		iv:  iv,
+		opt: &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+
+	if ex.opt.aggregateExecutorType != UnknownAggregateExecutorType &&
+		!isValidAggregateExecutorType(ex.opt.aggregateExecutorType, ex.f.Type()) {
+		return nil, ErrInvalidAggregateExecutor
+	}
+	return ex, nil
+}
+
+// NewAggregateWhileExecutor returns a new Executor for aggregate with early termination.
+//
+// This aggregates like foldl of NewAggregateExecutor(),
+// but checks the accumulated value by cond after each step,
+// if cond returns false, stops the aggregation and yields the accumulated value at that point.
+// The rest of it is not consumed, so this can work on infinite iterator.
+// If f is not appropriate for foldl, returns ErrInvalidAggregateExecutor.
+// If f or cond returns error, the iterator ends with the error.
+func NewAggregateWhileExecutor(f Aggregator, cond Filter, it Iterator, iv interface{}) (Executor, error) {
+	if !isValidAggregateExecutorType(LAggregateExecutorType, f.Type()) {
+		return nil, ErrInvalidAggregateExecutor
+	}
+	return &aggregateExecutor{
+		f:    f,
+		it:   it,
+		iv:   iv,
+		cond: cond,
+		opt: &executorOption{
+			aggregateExecutorOption: aggregateExecutorOption{
+				aggregateExecutorType: LAggregateExecutorType,
+			},
+		},
+	}, nil
+}
+
+// WithAggregateExecutorType sets AggregateExecutorType of Executor for aggregate.
+func WithAggregateExecutorType(t AggregateExecutorType) ExecutorOption {
+	return func(ex Executor) {
+		if ax, ok := ex.(*aggregateExecutor); ok {
+			ax.opt.aggregateExecutorType = t
+		}
+	}
+}
+
+func isValidAggregateExecutorType(aet AggregateExecutorType, at AggregatorType) bool {
+	switch aet {
+	case RAggregateExecutorType:
+		return at == RightAggregatorType || at == PerfectAggregatorType
+	case LAggregateExecutorType:
+		return at == LeftAggregatorType || at == PerfectAggregatorType
+	default:
+		return false
+	}
+}
+
+func (s *aggregateExecutor) executorType() AggregateExecutorType {
+	if s.opt.aggregateExecutorType != UnknownAggregateExecutorType {
+		return s.opt.aggregateExecutorType
+	}
+	switch s.f.Type() {
+	case RightAggregatorType:
+		return RAggregateExecutorType
+	case LeftAggregatorType, PerfectAggregatorType:
+		// the direction of a perfect aggregator cannot be determined by the signature
+		return LAggregateExecutorType
+	default:
+		return UnknownAggregateExecutorType
+	}
+}
+
+func (s *aggregateExecutor) Execute() (Iterator, error) {
+	switch s.executorType() {
+	case RAggregateExecutorType:
+		var isEOI bool
+		return NewIterator(func() (interface{}, error) {
+			if isEOI {
+				return nil, ErrEOI
+			}
+			isEOI = true
+			return s.foldr(s.iv)
+		})
+	case LAggregateExecutorType:
+		var isEOI bool
+		return NewIterator(func() (interface{}, error) {
+			if isEOI {
+				return nil, ErrEOI
+			}
+			isEOI = true
+			return s.foldl(s.iv)
+		})
+	default:
+		return nil, ErrInvalidAggregateExecutor
+	}
+}
+
+// foldr requires a -> b -> b
+func (s *aggregateExecutor) foldr(acc interface{}) (interface{}, error) {
+	x, err := s.it.Next()
+	if err == ErrEOI {
+		return acc, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.foldr(acc)
+	if err != nil {
+		return nil, err
+	}
+	return s.f.Apply(x, r)
+}
+
+// foldl requies b -> a -> b
+//
+// Loops instead of recursion not to grow the stack on a long stream.
+func (s *aggregateExecutor) foldl(acc interface{}) (interface{}, error) {
+	for {
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			return acc, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		r, err := s.f.Apply(acc, x)
+		if err != nil {
+			return nil, err
+		}
+		if s.cond != nil {
+			ok, err := s.cond.Apply(r)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return r, nil
+			}
+		}
+		acc = r
+	}
+}
+
+type (
+	windowAggregateExecutor struct {
+		size int
+		f    Aggregator
+		it   Iterator
+		iv   interface{}
+		opt  []ExecutorOption
+	}
+)
+
+// NewWindowAggregateExecutor returns a new Executor for tumbling window aggregate.
+//
+// This groups size consecutive elements into a window like NewChunkExecutor()
+// and yields the aggregated value of each window by f with initial value iv like NewAggregateExecutor(),
+// the last window may be shorter than size.
+// iv is shared by all windows.
+// If size is not positive, returns ErrInvalidSize.
+// If f is not appropriate for aggregate, returns ErrInvalidAggregateExecutor.
+func NewWindowAggregateExecutor(size int, f Aggregator, it Iterator, iv interface{}, opt ...ExecutorOption) (Executor, error) {
+	if size <= 0 {
+		return nil, ErrInvalidSize
+	}
+	if _, err := NewAggregateExecutor(f, it, iv, opt...); err != nil {
+		return nil, err
+	}
+	return &windowAggregateExecutor{
+		size: size,
+		f:    f,
+		it:   it,
+		iv:   iv,
+		opt:  opt,
+	}, nil
+}
+
+func (s *windowAggregateExecutor) Execute() (Iterator, error) {
+	chunks, err := (&chunkExecutor{
+		size: s.size,
+		it:   s.it,
+	}).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return NewIterator(func() (interface{}, error) {
+		xs, err := chunks.Next()
+		if err != nil {
+			return nil, err
+		}
+		ex, err := NewAggregateExecutor(s.f, MustNewIterator(xs), s.iv, s.opt...)
+		if err != nil {
+			return nil, err
+		}
+		it, err := ex.Execute()
+		if err != nil {
+			return nil, err
+		}
+		return it.Next()
+	})
+}
+
+type (
+	scanExecutor struct {
+		f  Aggregator
+		it Iterator
+		iv interface{}
+	}
+)
+
+// NewScanExecutor returns a new Executor for scan.
+//
+// This is like foldl, but yields every intermediate accumulated value.
+// f is a func(B, A) (B, error) or func(B, A) B.
+// If f is not appropriate for foldl, returns ErrInvalidAggregateExecutor.
+// If f returns error, the iterator ends here.
+func NewScanExecutor(f Aggregator, it Iterator, iv interface{}) (Executor, error) {
+	if !isValidAggregateExecutorType(LAggregateExecutorType, f.Type()) {
+		return nil, ErrInvalidAggregateExecutor
+	}
+	return &scanExecutor{
+		f:  f,
+		it: it,
+		iv: iv,
+	}, nil
+}
+
+func (s *scanExecutor) Execute() (Iterator, error) {
+	acc := s.iv
+	return NewIterator(func() (interface{}, error) {
+		x, err := s.it.Next()
+		if err != nil {
+			return nil, err
+		}
+		r, err := s.f.Apply(acc, x)
+		if err != nil {
+			return nil, err
+		}
+		acc = r
+		return r, nil
+	})
+}
+
+type (
+	compareExecutor struct {
+		f   Comparator
+		it  Iterator
+		opt *executorOption
+	}
+
+	compareExecutorOption struct {
+		isStrict bool
 	}
 )
 
+// NewCompareExecutor returns a new Executor for sort.
+//
+// The sort is stable, equal elements keep their original order.
+//
+// If f returns error, regard the right argument is larger by default,
+// see WithCompareExecutorStrict().
+// This buffers all elements of it, see WithExecutorMaxBuffer().
+func NewCompareExecutor(f Comparator, it Iterator, opt ...ExecutorOption) Executor {
+	ex := &compareExecutor{
+		f:   f,
+		it:  it,
+		opt: &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+	return ex
+}
+
+// WithCompareExecutorStrict sets whether the Executor for sort aborts on the error from the comparator.
+// If isStrict is true, the iterator yields the first error of the comparator instead of the sorted elements.
+func WithCompareExecutorStrict(isStrict bool) ExecutorOption {
+	return func(ex Executor) {
+		if cx, ok := ex.(*compareExecutor); ok {
+			cx.opt.isStrict = isStrict
+		}
+	}
+}
+
+func (s *compareExecutor) Execute() (Iterator, error) {
+	var (
+		xs = []interface{}{}
+		c  = s.it.Channel()
+	)
+	for x := range c.C() {
+		xs = append(xs, x)
+		if s.opt.isOverflow(len(xs)) {
+			c.Close()
+			return newIterator(func() (interface{}, error) {
+				return nil, ErrBufferOverflow
+			}), nil
+		}
+	}
+	if !s.opt.isStrict {
+		sort.SliceStable(xs, func(i, j int) bool {
+			v, _ := s.f.Apply(xs[i], xs[j]) // Note: ignore error. maybe error is unnecessary
+			return v
+		})
+		return NewIterator(xs)
+	}
+
+	var cerr error
+	sort.SliceStable(xs, func(i, j int) bool {
+		if cerr != nil {
+			return false
+		}
+		v, err := s.f.Apply(xs[i], xs[j])
+		if err != nil {
+			cerr = err
+			return false
+		}
+		return v
+	})
+	if cerr != nil {
+		return newIterator(func() (interface{}, error) {
+			return nil, cerr
+		}), nil
+	}
+	return NewIterator(xs)
+}
+
 type (
-	mapExecutor struct {
-		f  Mapper
-		it Iterator
+	topNExecutor struct {
+		n        int
+		f        Comparator
+		it       Iterator
+		isBottom bool
+	}
+
+	// selectHeap is a heap whose root is the element that is dropped first on selecting.
+	selectHeap struct {
+		xs   []interface{}
+		less func(x, y interface{}) bool
 	}
 )
 
-// NewMapExecutor returns a new Executor for map.
+func (s *selectHeap) Len() int           { return len(s.xs) }
+func (s *selectHeap) Less(i, j int) bool { return s.less(s.xs[i], s.xs[j]) }
+func (s *selectHeap) Swap(i, j int)      { s.xs[i], s.xs[j] = s.xs[j], s.xs[i] }
+func (s *selectHeap) Push(x interface{}) { s.xs = append(s.xs, x) }
+func (s *selectHeap) Pop() interface{} {
+	x := s.xs[len(s.xs)-1]
+	s.xs = s.xs[:len(s.xs)-1]
+	return x
+}
+func (s *selectHeap) top() interface{} { return s.xs[0] }
+func (s *selectHeap) replaceTop(x interface{}) {
+	s.xs[0] = x
+	heap.Fix(s, 0)
+}
+
+// NewTopNExecutor returns a new Executor that selects the n largest elements by f, a "less" function.
 //
-// If f returns error, the argument of f is ignored, this does not yield it.
-func NewMapExecutor(f Mapper, it Iterator) Executor {
-	return &mapExecutor{
-		f:  f,
-		it: it,
+// This keeps at most n elements in memory, so it costs O(m log n) for m elements instead of sorting all of them.
+// The selected elements are yielded from the largest to the smallest.
+// The order of equal elements is not specified.
+// If f returns error, regard the right argument is larger.
+// If n is not positive, returns ErrInvalidSize.
+func NewTopNExecutor(n int, f Comparator, it Iterator) (Executor, error) {
+	return newTopNExecutor(n, f, it, false)
+}
+
+// NewBottomNExecutor returns a new Executor that selects the n smallest elements by f, a "less" function.
+//
+// The selected elements are yielded from the smallest to the largest.
+// See NewTopNExecutor().
+func NewBottomNExecutor(n int, f Comparator, it Iterator) (Executor, error) {
+	return newTopNExecutor(n, f, it, true)
+}
+
+func newTopNExecutor(n int, f Comparator, it Iterator, isBottom bool) (Executor, error) {
+	if n <= 0 {
+		return nil, ErrInvalidSize
 	}
+	return &topNExecutor{
+		n:        n,
+		f:        f,
+		it:       it,
+		isBottom: isBottom,
+	}, nil
 }
 
-func (s *mapExecutor) Execute() (Iterator, error) {
-	var f func() (interface{}, error)
-	f = func() (interface{}, error) {
+func (s *topNExecutor) less(x, y interface{}) bool {
+	if s.isBottom {
+		x, y = y, x
+	}
+	v, _ := s.f.Apply(x, y) // Note: ignore error like Sort
+	return v
+}
+
+// drain selects n elements from it and returns them in the order of yield.
+func (s *topNExecutor) drain() ([]interface{}, error) {
+	h := &selectHeap{
+		xs:   []interface{}{},
+		less: s.less,
+	}
+	for {
 		x, err := s.it.Next()
+		if err == ErrEOI {
+			break
+		}
 		if err != nil {
 			return nil, err
 		}
-		v, err := s.f.Apply(x)
-		if err != nil {
-			// ignore this value
-			return f()
+		if h.Len() < s.n {
+			heap.Push(h, x)
+			continue
+		}
+		if s.less(h.top(), x) {
+			h.replaceTop(x)
 		}
-		return v, nil
 	}
-	return NewIterator(f)
+	xs := make([]interface{}, h.Len())
+	for i := len(xs) - 1; i >= 0; i-- {
+		xs[i] = heap.Pop(h)
+	}
+	return xs, nil
+}
+
+func (s *topNExecutor) Execute() (Iterator, error) {
+	var (
+		xs []interface{}
+		i  int
+	)
+	return NewIterator(func() (interface{}, error) {
+		if xs == nil {
+			var err error
+			if xs, err = s.drain(); err != nil {
+				return nil, err
+			}
+		}
+		if i >= len(xs) {
+			return nil, ErrEOI
+		}
+		i++
+		return xs[i-1], nil
+	})
 }
 
 type (
-	filterExecutor struct {
-		f  Filter
+	flatMapExecutor struct {
+		f  Mapper
 		it Iterator
 	}
 )
 
-// NewFilterExecutor returns a new Executor for filter.
+// NewFlatMapExecutor returns a new Executor for flat map.
 //
-// If f returns error, the iterator ends here.
-func NewFilterExecutor(f Filter, it Iterator) Executor {
-	return &filterExecutor{
+// This converts each element by f and flattens the results like NewFlatExecutor().
+// If f returns error, the argument of f is ignored, this does not yield it.
+func NewFlatMapExecutor(f Mapper, it Iterator) Executor {
+	return &flatMapExecutor{
 		f:  f,
 		it: it,
 	}
 }
 
-func (s *filterExecutor) Execute() (Iterator, error) {
-	var f func() (interface{}, error)
-	f = func() (interface{}, error) {
+func (s *flatMapExecutor) Execute() (Iterator, error) {
+	mit, err := NewMapExecutor(s.f, s.it).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return NewFlatExecutor(mit).Execute()
+}
+
+type (
+	concatExecutor struct {
+		its []Iterator
+	}
+)
+
+// NewConcatExecutor returns a new Executor for concat.
+//
+// This yields all elements of it and then all elements of others sequentially.
+// See Concat().
+func NewConcatExecutor(it Iterator, others ...Iterator) Executor {
+	return &concatExecutor{
+		its: append([]Iterator{it}, others...),
+	}
+}
+
+func (s *concatExecutor) Execute() (Iterator, error) { return Concat(s.its...) }
+
+type (
+	enumerateExecutor struct {
+		start int
+		it    Iterator
+	}
+)
+
+// NewEnumerateExecutor returns a new Executor for enumerate.
+//
+// This yields Tuple(index, element), index starts from start.
+func NewEnumerateExecutor(start int, it Iterator) Executor {
+	return &enumerateExecutor{
+		start: start,
+		it:    it,
+	}
+}
+
+func (s *enumerateExecutor) Execute() (Iterator, error) {
+	i := s.start
+	return NewIterator(func() (interface{}, error) {
 		x, err := s.it.Next()
 		if err != nil {
 			return nil, err
 		}
-		v, err := s.f.Apply(x)
+		defer func() { i++ }()
+		return NewTuple(i, x), nil
+	})
+}
+
+type (
+	reverseExecutor struct {
+		it  Iterator
+		opt *executorOption
+	}
+)
+
+// NewReverseExecutor returns a new Executor for reverse.
+//
+// This buffers all elements of it, so it must be finite,
+// and yields them in reverse order.
+// If it yields error, the iterator ends here.
+// See WithExecutorMaxBuffer() to limit the buffer.
+func NewReverseExecutor(it Iterator, opt ...ExecutorOption) Executor {
+	ex := &reverseExecutor{
+		it:  it,
+		opt: &executorOption{},
+	}
+	for _, o := range opt {
+		o(ex)
+	}
+	return ex
+}
+
+func (s *reverseExecutor) drain() ([]interface{}, error) {
+	xs := []interface{}{}
+	for {
+		x, err := s.it.Next()
+		if err == ErrEOI {
+			return xs, nil
+		}
 		if err != nil {
-			// ends iterator
 			return nil, err
 		}
-		if !v {
-			// skip
-			return f()
+		xs = append(xs, x)
+		if s.opt.isOverflow(len(xs)) {
+			return nil, ErrBufferOverflow
 		}
-		return x, nil
 	}
-	return NewIterator(f)
 }
 
-var (
-	ErrInvalidAggregateExecutor = errors.New("invalid aggregate executor")
-)
+func (s *reverseExecutor) Execute() (Iterator, error) {
+	var (
+		xs []interface{}
+		i  int
+	)
+	return NewIterator(func() (interface{}, error) {
+		if xs == nil {
+			var err error
+			if xs, err = s.drain(); err != nil {
+				return nil, err
+			}
+			i = len(xs)
+		}
+		if i <= 0 {
+			return nil, ErrEOI
+		}
+		i--
+		return xs[i], nil
+	})
+}
 
 type (
-	aggregateExecutor struct {
-		f   Aggregator
-		it  Iterator
-		iv  interface{}
-		opt *executorOption
+	flatDeepExecutor struct {
+		depth int
+		it    Iterator
+		opt   *executorOption
 	}
 
-	aggregateExecutorOption struct {
-		aggregateExecutorType AggregateExecutorType
+	flatExecutorOption struct {
+		isTupleFlattened  bool
+		isStringFlattened bool
 	}
 
-	// AgregateExecutorType is a type of aggregation.
-	AggregateExecutorType int
-)
-
-const (
-	UnknownAggregateExecutorType AggregateExecutorType = iota
-	// RAggregateExecutorType is foldr.
-	RAggregateExecutorType
-	// LAggregateExecutorType is foldl.
-	LAggregateExecutorType
+	// flatDeepFrame is an iterator of the nested elements with the remaining depth.
+	flatDeepFrame struct {
+		it    Iterator
+		depth int
+	}
 )
 
-// NewAggregateExecutor returns a new Executor for aggregate.
+// NewFlatDeepExecutor returns a new Executor for recursive flat.
 //
-// If f is not appropriate for aggregate, returns ErrInvalidAggregateExecutor.
-func NewAggregateExecutor(f Aggregator, it Iterator, iv interface{}, opt ...ExecutorOption) (Executor, error) {
-	ex := &aggregateExecutor{
-		f:   f,
-		it:  it,
-		iv:  iv,
-		opt: &executorOption{},
+// This flattens slices and arrays recursively up to depth levels,
+// if depth is negative, flattens them fully.
+// The other elements, including the elements nested deeper than depth, are yielded as they are
+// regardless of the depth they appear in.
+// Tuples are also flattened if WithFlatExecutorTuple(true) is given.
+// If it causes error, iteration ends here.
+func NewFlatDeepExecutor(depth int, it Iterator, opt ...ExecutorOption) Executor {
+	ex := &flatDeepExecutor{
+		depth: depth,
+		it:    it,
+		opt:   &executorOption{},
 	}
 	for _, o := range opt {
 		o(ex)
 	}
-
-	if ex.opt.aggregateExecutorType != UnknownAggregateExecutorType &&
-		!isValidAggregateExecutorType(ex.opt.aggregateExecutorType, ex.f.Type()) {
-		return nil, ErrInvalidAggregateExecutor
-	}
-	return ex, nil
+	return ex
 }
 
-// WithAggregateExecutorType sets AggregateExecutorType of Executor for aggregate.
-func WithAggregateExecutorType(t AggregateExecutorType) ExecutorOption {
+// WithFlatExecutorTuple sets whether the Executor for recursive flat flattens Tuples.
+func WithFlatExecutorTuple(isFlattened bool) ExecutorOption {
 	return func(ex Executor) {
-		if ax, ok := ex.(*aggregateExecutor); ok {
-			ax.opt.aggregateExecutorType = t
+		if fx, ok := ex.(*flatDeepExecutor); ok {
+			fx.opt.isTupleFlattened = isFlattened
 		}
 	}
 }
 
-func isValidAggregateExecutorType(aet AggregateExecutorType, at AggregatorType) bool {
-	switch aet {
-	case RAggregateExecutorType:
-		return at == RightAggregatorType || at == PerfectAggregatorType
-	case LAggregateExecutorType:
-		return at == LeftAggregatorType || at == PerfectAggregatorType
-	default:
-		return false
+// expand returns an iterator of the elements of x if x should be flattened.
+func (s *flatDeepExecutor) expand(x interface{}) (Iterator, bool) {
+	if t, ok := x.(Tuple); ok {
+		if !s.opt.isTupleFlattened {
+			return nil, false
+		}
+		return MustNewIterator(t.ToSlice()), true
 	}
-}
-
-func (s *aggregateExecutor) executorType() AggregateExecutorType {
-	if s.opt.aggregateExecutorType != UnknownAggregateExecutorType {
-		return s.opt.aggregateExecutorType
+	if x == nil {
+		return nil, false
 	}
-	switch s.f.Type() {
-	case RightAggregatorType:
-		return RAggregateExecutorType
-	case LeftAggregatorType, PerfectAggregatorType:
-		return LAggregateExecutorType
+	switch reflect.TypeOf(x).Kind() {
+	case reflect.Slice, reflect.Array:
+		return MustNewIterator(x), true
 	default:
-		return UnknownAggregateExecutorType
+		return nil, false
 	}
 }
 
-func (s *aggregateExecutor) Execute() (Iterator, error) {
-	switch s.executorType() {
-	case RAggregateExecutorType:
-		var isEOI bool
-		return NewIterator(func() (interface{}, error) {
-			if isEOI {
-				return nil, ErrEOI
+func (s *flatDeepExecutor) Execute() (Iterator, error) {
+	stack := []*flatDeepFrame{{
+		it:    s.it,
+		depth: s.depth,
+	}}
+	return NewIterator(func() (interface{}, error) {
+		for {
+			top := stack[len(stack)-1]
+			x, err := top.it.Next()
+			if err == ErrEOI && len(stack) > 1 {
+				// back to the outer level
+				stack = stack[:len(stack)-1]
+				continue
 			}
-			isEOI = true
-			return s.foldr(s.iv)
-		})
-	case LAggregateExecutorType:
-		var isEOI bool
-		return NewIterator(func() (interface{}, error) {
-			if isEOI {
-				return nil, ErrEOI
+			if err != nil {
+				return nil, err
 			}
-			isEOI = true
-			return s.foldl(s.iv)
-		})
-	default:
-		return nil, ErrInvalidAggregateExecutor
-	}
+			if top.depth != 0 {
+				if xs, ok := s.expand(x); ok {
+					stack = append(stack, &flatDeepFrame{
+						it:    xs,
+						depth: top.depth - 1,
+					})
+					continue
+				}
+			}
+			return x, nil
+		}
+	})
 }
 
-// foldr requires a -> b -> b
-func (s *aggregateExecutor) foldr(acc interface{}) (interface{}, error) {
-	x, err := s.it.Next()
-	if err == ErrEOI {
-		return acc, nil
+type (
+	flatExecutor struct {
+		it  Iterator
+		opt *executorOption
 	}
-	if err != nil {
-		return nil, err
+)
+
+// NewFlatExecutor returns a new Executor for flat.
+//
+// This creates a new iterator by NewIterator() from each element and yields from them sequentially.
+// Strings are yielded as they are, or flattened into runes if WithFlatExecutorString(true) is given.
+// If it or element of it causes error, iteration ends here.
+func NewFlatExecutor(it Iterator, opt ...ExecutorOption) Executor {
+	ex := &flatExecutor{
+		it:  it,
+		opt: &executorOption{},
 	}
-	r, err := s.foldr(acc)
-	if err != nil {
-		return nil, err
+	for _, o := range opt {
+		o(ex)
 	}
-	return s.f.Apply(x, r)
+	return ex
 }
 
-// foldl requies b -> a -> b
-func (s *aggregateExecutor) foldl(acc interface{}) (interface{}, error) {
-	x, err := s.it.Next()
-	if err == ErrEOI {
-		return acc, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-	r, err := s.f.Apply(acc, x)
-	if err != nil {
-		return nil, err
+// WithFlatExecutorString sets whether the Executor for flat flattens strings into runes.
+func WithFlatExecutorString(isFlattened bool) ExecutorOption {
+	return func(ex Executor) {
+		if fx, ok := ex.(*flatExecutor); ok {
+			fx.opt.isStringFlattened = isFlattened
+		}
 	}
-	return s.foldl(r)
 }
 
 type (
-	compareExecutor struct {
-		f  Comparator
+	flatChannelExecutor struct {
 		it Iterator
 	}
 )
 
-// NewCompareExecutor returns a new Executor for sort.
+// NewFlatChannelExecutor returns a new Executor for flat of channels.
 //
-// If f returns error, regard the right argument is larger.
-func NewCompareExecutor(f Comparator, it Iterator) Executor {
-	return &compareExecutor{
-		f:  f,
+// Each element of it must be a receivable channel,
+// this receives from the channels in turn and yields the received values.
+// The closed channel is regarded as the end of the channel, then moves to the next element.
+// If an element is not a receivable channel, the iterator ends with ErrCannotCreateIterator.
+// If it causes error, iteration ends here.
+func NewFlatChannelExecutor(it Iterator) Executor {
+	return &flatChannelExecutor{
 		it: it,
 	}
 }
 
-func (s *compareExecutor) Execute() (Iterator, error) {
-	xs := []interface{}{}
-	for x := range s.it.Channel().C() {
-		xs = append(xs, x)
-	}
-	sort.SliceStable(xs, func(i, j int) bool {
-		v, _ := s.f.Apply(xs[i], xs[j]) // Note: ignore error. maybe error is unnecessary
-		return v
+func (s *flatChannelExecutor) Execute() (Iterator, error) {
+	var head IteratorFunc
+	return NewIterator(func() (interface{}, error) {
+		for {
+			if head == nil {
+				x, err := s.it.Next()
+				if err != nil {
+					return nil, err
+				}
+				if x == nil {
+					return nil, ErrCannotCreateIterator
+				}
+				if head, err = newChanIteratorFunc(x); err != nil {
+					return nil, err
+				}
+			}
+			v, err := head()
+			if err == ErrEOI {
+				// next channel
+				head = nil
+				continue
+			}
+			return v, err
+		}
 	})
-	return NewIterator(xs)
 }
 
-type (
-	flatExecutor struct {
-		it Iterator
-	}
-)
-
-// NewFlatExecutor returns a new Executor for flat.
-//
-// This creates a new iterator by NewIterator() from each element and yields from them sequentially.
-// If it or element of it causes error, iteration ends here.
-func NewFlatExecutor(it Iterator) Executor {
-	return &flatExecutor{
-		it: it,
+func (s *flatExecutor) newIterator(x interface{}) (Iterator, error) {
+	if v, ok := x.(string); ok && s.opt.isStringFlattened {
+		return NewIterator([]rune(v))
 	}
+	return NewIterator(x)
 }
 
 func (s *flatExecutor) Execute() (Iterator, error) {
@@ -288,7 +1896,7 @@ func (s *flatExecutor) Execute() (Iterator, error) {
 			if top, err = s.it.Next(); err != nil {
 				return nil, err
 			}
-			if head, err = NewIterator(top); err != nil {
+			if head, err = s.newIterator(top); err != nil {
 				return nil, err
 			}
 		}
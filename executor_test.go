@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/berquerant/circle"
 
@@ -81,6 +82,36 @@ func TestMapExecutor(t *testing.T) {
 			assert.Equal(t, circle.ErrEOI, err)
 		}
 	})
+
+	t.Run("consecutive errors", func(t *testing.T) {
+		const n = 100000
+		var i int
+		it := circle.MustNewIterator(func() (interface{}, error) {
+			if i > n {
+				return nil, circle.ErrEOI
+			}
+			i++
+			return i, nil
+		})
+		f, err := circle.NewMapper(func(x int) (int, error) {
+			if x <= n {
+				return 0, errors.New("skip")
+			}
+			return x, nil
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewMapExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		{
+			v, err := exit.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, n+1, v)
+		}
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
 }
 
 func ExampleNewFilterExecutor() {
@@ -148,6 +179,53 @@ func TestFilterExecutor(t *testing.T) {
 			assert.Equal(t, errors.New("negative"), err)
 		}
 	})
+
+	t.Run("collect errors", func(t *testing.T) {
+		f, err := circle.NewFilter(func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return x&1 == 1, nil
+		})
+		assert.Nil(t, err)
+		errs := []error{}
+		exit, err := circle.NewFilterExecutor(f, circle.MustNewIterator([]int{1, -1, 2, 3, -2}),
+			circle.WithExecutorErrorCollector(func(err error) {
+				errs = append(errs, err)
+			})).Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 3}, got))
+		assert.Equal(t, []error{errors.New("negative"), errors.New("negative")}, errs)
+	})
+
+	t.Run("consecutive filtered out", func(t *testing.T) {
+		const n = 1000000
+		var i int
+		it := circle.MustNewIterator(func() (interface{}, error) {
+			if i > n {
+				return nil, circle.ErrEOI
+			}
+			i++
+			return i, nil
+		})
+		f, err := circle.NewFilter(func(x int) bool {
+			return x > n
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewFilterExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		{
+			v, err := exit.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, n+1, v)
+		}
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
 }
 
 func ExampleNewAggregateExecutor_right() {
@@ -189,6 +267,24 @@ func TestAggregateExecutor(t *testing.T) {
 	} {
 		t.Run(name, tc)
 	}
+
+	t.Run("perfect default", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(x, y string) string {
+			return fmt.Sprintf("(%s+%s)", x, y)
+		})
+		assert.Nil(t, err)
+		aggregate := func(opt ...circle.ExecutorOption) interface{} {
+			ex, err := circle.NewAggregateExecutor(f, circle.MustNewIterator([]string{"a", "b"}), "iv", opt...)
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			v, err := exit.Next()
+			assert.Nil(t, err)
+			return v
+		}
+		assert.Equal(t, "((iv+a)+b)", aggregate())
+		assert.Equal(t, "(a+(b+iv))", aggregate(circle.WithAggregateExecutorType(circle.RAggregateExecutorType)))
+	})
 }
 
 func testLeftAggregateExecutor(t *testing.T) {
@@ -308,6 +404,35 @@ func TestCompareExecutor(t *testing.T) {
 		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3, 4, 5}, xs))
 		assert.Nil(t, c.Err())
 	})
+
+	t.Run("stable", func(t *testing.T) {
+		type item struct {
+			key int
+			tag string
+		}
+		it, err := circle.NewIterator([]item{
+			{key: 2, tag: "a"},
+			{key: 1, tag: "b"},
+			{key: 2, tag: "c"},
+			{key: 1, tag: "d"},
+			{key: 2, tag: "e"},
+			{key: 1, tag: "f"},
+		})
+		assert.Nil(t, err)
+		f, err := circle.NewComparator(func(x, y item) bool {
+			return x.key < y.key
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewCompareExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		tags := []string{}
+		for v := range c.C() {
+			tags = append(tags, v.(item).tag)
+		}
+		assert.Equal(t, "", cmp.Diff([]string{"b", "d", "f", "a", "c", "e"}, tags))
+		assert.Nil(t, c.Err())
+	})
 }
 
 func ExampleNewFlatExecutor() {
@@ -361,11 +486,32 @@ func TestFlatExecutor(t *testing.T) {
 		"noop":    testFlatExecutorNoop,
 		"onestep": testFlatExecutorFlatOneStep,
 		"nil":     testFlatExecutorNil,
+		"string":  testFlatExecutorString,
 	} {
 		t.Run(name, tc)
 	}
 }
 
+func testFlatExecutorString(t *testing.T) {
+	t.Run("pass through", func(t *testing.T) {
+		exit, err := circle.NewFlatExecutor(circle.MustNewIterator([]string{"ab", "", "c"})).Execute()
+		assert.Nil(t, err)
+		got, err := circle.Collect(exit)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{"ab", "", "c"}, got))
+	})
+	t.Run("flatten", func(t *testing.T) {
+		exit, err := circle.NewFlatExecutor(
+			circle.MustNewIterator([]string{"ab", "", "cあ"}),
+			circle.WithFlatExecutorString(true),
+		).Execute()
+		assert.Nil(t, err)
+		got, err := circle.Collect(exit)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{'a', 'b', 'c', 'あ'}, got))
+	})
+}
+
 func testFlatExecutorNil(t *testing.T) {
 	it, err := circle.NewIterator(nil)
 	assert.Nil(t, err)
@@ -404,3 +550,979 @@ func testFlatExecutorFlatOneStep(t *testing.T) {
 	assert.Equal(t, "", cmp.Diff([]int{1, 2, 3, 4, 5, 6}, xs))
 	assert.Nil(t, c.Err())
 }
+
+func TestFlatChannelExecutor(t *testing.T) {
+	newChan := func(xs ...int) <-chan int {
+		c := make(chan int, len(xs))
+		for _, x := range xs {
+			c <- x
+		}
+		close(c)
+		return c
+	}
+
+	t.Run("flatten", func(t *testing.T) {
+		exit, err := circle.NewFlatChannelExecutor(
+			circle.MustNewIterator([]<-chan int{newChan(1, 2), newChan(), newChan(3)}),
+		).Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+	})
+
+	t.Run("fan in", func(t *testing.T) {
+		subs := make(chan chan int, 2)
+		for i := 0; i < 2; i++ {
+			c := make(chan int)
+			go func(i int) {
+				defer close(c)
+				for j := 0; j < 3; j++ {
+					c <- i*10 + j
+				}
+			}(i)
+			subs <- c
+		}
+		close(subs)
+		exit, err := circle.NewFlatChannelExecutor(circle.MustNewIterator(subs)).Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{0, 1, 2, 10, 11, 12}, got))
+	})
+
+	t.Run("not receivable", func(t *testing.T) {
+		for _, x := range []interface{}{
+			make(chan<- int),
+			1,
+			nil,
+		} {
+			exit, err := circle.NewFlatChannelExecutor(
+				circle.MustNewIterator([]interface{}{newChan(1), x}),
+			).Execute()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(exit)
+			assert.Equal(t, circle.ErrCannotCreateIterator, err)
+			assert.Equal(t, "", cmp.Diff([]int{1}, got))
+		}
+	})
+}
+
+func TestFlatDeepExecutor(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		depth   int
+		src     interface{}
+		isTuple bool
+		want    []interface{}
+	}{
+		{
+			title: "nil",
+			depth: -1,
+			want:  []interface{}{},
+		},
+		{
+			title: "zero depth",
+			depth: 0,
+			src:   [][]int{{1}, {2, 3}},
+			want:  []interface{}{[]int{1}, []int{2, 3}},
+		},
+		{
+			title: "2d",
+			depth: 1,
+			src:   [][]int{{1}, {2, 3}, {}},
+			want:  []interface{}{1, 2, 3},
+		},
+		{
+			title: "3d fully",
+			depth: -1,
+			src:   [][][]int{{{1}, {2, 3}}, {{4}}},
+			want:  []interface{}{1, 2, 3, 4},
+		},
+		{
+			title: "3d one level",
+			depth: 1,
+			src:   [][][]int{{{1}, {2, 3}}, {{4}}},
+			want:  []interface{}{[]int{1}, []int{2, 3}, []int{4}},
+		},
+		{
+			title: "mixed",
+			depth: -1,
+			src:   []interface{}{1, []interface{}{2, []int{3, 4}, "five"}, [1]int{6}},
+			want:  []interface{}{1, 2, 3, 4, "five", 6},
+		},
+		{
+			title: "mixed two levels",
+			depth: 2,
+			src:   []interface{}{1, []interface{}{2, []interface{}{3, []int{4}}}},
+			want:  []interface{}{1, 2, 3, []int{4}},
+		},
+		{
+			title: "tuple passed through",
+			depth: -1,
+			src:   []interface{}{circle.NewTuple(1, 2), []int{3}},
+			want:  []interface{}{circle.NewTuple(1, 2), 3},
+		},
+		{
+			title:   "tuple flattened",
+			depth:   -1,
+			src:     []interface{}{circle.NewTuple(1, []int{2}), []int{3}},
+			isTuple: true,
+			want:    []interface{}{1, 2, 3},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			exit, err := circle.NewFlatDeepExecutor(tc.depth, circle.MustNewIterator(tc.src),
+				circle.WithFlatExecutorTuple(tc.isTuple)).Execute()
+			assert.Nil(t, err)
+			got, err := circle.Collect(exit)
+			assert.Nil(t, err)
+			assert.Equal(t, fmt.Sprint(tc.want), fmt.Sprint(got))
+		})
+	}
+}
+
+func TestTakeWhileExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		it, err := circle.NewIterator(nil)
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(int) bool { return true })
+		assert.Nil(t, err)
+		exit, err := circle.NewTakeWhileExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
+
+	t.Run("do", func(t *testing.T) {
+		var i int
+		it, err := circle.NewIterator(func() (interface{}, error) {
+			// infinite iterator
+			defer func() { i++ }()
+			return i, nil
+		})
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(x int) bool { return x < 3 })
+		assert.Nil(t, err)
+		exit, err := circle.NewTakeWhileExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		xs := []int{}
+		for v := range c.C() {
+			xs = append(xs, v.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{0, 1, 2}, xs))
+		assert.Nil(t, c.Err())
+		assert.Equal(t, 4, i, "should not consume after the predicate fails")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{1, 2, -1, 3})
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return true, nil
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewTakeWhileExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		xs := []int{}
+		for v := range c.C() {
+			xs = append(xs, v.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, xs))
+		assert.Equal(t, errors.New("negative"), c.Err())
+	})
+}
+
+func TestDropWhileExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		it, err := circle.NewIterator(nil)
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(int) bool { return true })
+		assert.Nil(t, err)
+		exit, err := circle.NewDropWhileExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
+
+	t.Run("do", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{1, 2, 3, -1, 1})
+		assert.Nil(t, err)
+		var calls int
+		f, err := circle.NewFilter(func(x int) (bool, error) {
+			calls++
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return x < 3, nil
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewDropWhileExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		xs := []int{}
+		for v := range c.C() {
+			xs = append(xs, v.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{3, -1, 1}, xs))
+		assert.Nil(t, c.Err())
+		assert.Equal(t, 3, calls, "should not call f after the predicate fails")
+	})
+}
+
+func TestDistinctWindowExecutor(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewDistinctWindowExecutor(0, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	for _, tc := range []struct {
+		title string
+		n     int
+		src   []int
+		want  []int
+	}{
+		{
+			title: "nil",
+			n:     2,
+			want:  []int{},
+		},
+		{
+			title: "window 1 is dedup",
+			n:     1,
+			src:   []int{1, 1, 2, 2, 1},
+			want:  []int{1, 2, 1},
+		},
+		{
+			title: "fall out of window",
+			n:     2,
+			src:   []int{1, 2, 1, 3, 1, 2, 2, 4},
+			want:  []int{1, 2, 3, 1, 2, 4},
+		},
+		{
+			title: "large window is distinct",
+			n:     10,
+			src:   []int{3, 1, 3, 2, 1, 4},
+			want:  []int{3, 1, 2, 4},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			ex, err := circle.NewDistinctWindowExecutor(tc.n, circle.MustNewIterator(tc.src))
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(exit)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+
+	t.Run("not hashable", func(t *testing.T) {
+		ex, err := circle.NewDistinctWindowExecutor(2, circle.MustNewIterator([]interface{}{1, []int{}}))
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.True(t, errors.Is(err, circle.ErrNotHashable))
+	})
+}
+
+func TestDistinctExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		it, err := circle.NewIterator(nil)
+		assert.Nil(t, err)
+		exit, err := circle.NewDistinctExecutor(it).Execute()
+		assert.Nil(t, err)
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
+
+	t.Run("do", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{3, 1, 3, 2, 1, 4})
+		assert.Nil(t, err)
+		exit, err := circle.NewDistinctExecutor(it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		xs := []int{}
+		for v := range c.C() {
+			xs = append(xs, v.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{3, 1, 2, 4}, xs))
+		assert.Nil(t, c.Err())
+	})
+
+	t.Run("not hashable", func(t *testing.T) {
+		it, err := circle.NewIterator([]interface{}{1, map[string]int{}})
+		assert.Nil(t, err)
+		exit, err := circle.NewDistinctExecutor(it).Execute()
+		assert.Nil(t, err)
+		{
+			v, err := exit.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, v)
+		}
+		{
+			_, err := exit.Next()
+			assert.True(t, errors.Is(err, circle.ErrNotHashable))
+		}
+	})
+}
+
+func TestDistinctByExecutor(t *testing.T) {
+	it, err := circle.NewIterator([]string{"apple", "avocado", "banana", "cherry", "blueberry"})
+	assert.Nil(t, err)
+	f, err := circle.NewMapper(func(x string) byte { return x[0] })
+	assert.Nil(t, err)
+	exit, err := circle.NewDistinctByExecutor(f, it).Execute()
+	assert.Nil(t, err)
+	c := exit.Channel()
+	xs := []string{}
+	for v := range c.C() {
+		xs = append(xs, v.(string))
+	}
+	assert.Equal(t, "", cmp.Diff([]string{"apple", "banana", "cherry"}, xs))
+	assert.Nil(t, c.Err())
+}
+
+func TestDedupExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		exit, err := circle.NewDedupExecutor(circle.MustNewIterator(nil)).Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		exit, err := circle.NewDedupExecutor(circle.MustNewIterator([]int{1, 1, 2, 2, 2, 1, 3, 3})).Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 1, 3}, got))
+	})
+
+	t.Run("not comparable", func(t *testing.T) {
+		exit, err := circle.NewDedupExecutor(circle.MustNewIterator([][]int{{1}, {1}})).Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.True(t, errors.Is(err, circle.ErrNotHashable))
+	})
+}
+
+func TestDedupByExecutor(t *testing.T) {
+	it, err := circle.NewIterator([]string{"apple", "avocado", "banana", "apricot", "blueberry", "cherry"})
+	assert.Nil(t, err)
+	f, err := circle.NewMapper(func(x string) byte { return x[0] })
+	assert.Nil(t, err)
+	exit, err := circle.NewDedupByExecutor(f, it).Execute()
+	assert.Nil(t, err)
+	c := exit.Channel()
+	xs := []string{}
+	for v := range c.C() {
+		xs = append(xs, v.(string))
+	}
+	assert.Equal(t, "", cmp.Diff([]string{"apple", "banana", "apricot", "blueberry", "cherry"}, xs))
+	assert.Nil(t, c.Err())
+}
+
+func TestIntersperseExecutor(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		src   []int
+		want  []int
+	}{
+		{
+			title: "empty",
+			want:  []int{},
+		},
+		{
+			title: "single",
+			src:   []int{1},
+			want:  []int{1},
+		},
+		{
+			title: "do",
+			src:   []int{1, 2, 3},
+			want:  []int{1, 0, 2, 0, 3},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			exit, err := circle.NewIntersperseExecutor(0, circle.MustNewIterator(tc.src)).Execute()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(exit)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+}
+
+func TestGroupByExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		it, err := circle.NewIterator(nil)
+		assert.Nil(t, err)
+		f, err := circle.NewMapper(func(x int) int { return x })
+		assert.Nil(t, err)
+		exit, err := circle.NewGroupByExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		{
+			_, err := exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
+
+	t.Run("do", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{1, 2, 3, 4, -1, 5})
+		assert.Nil(t, err)
+		f, err := circle.NewMapper(func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return x&1 == 1, nil
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewGroupByExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		got := []string{}
+		for v := range c.C() {
+			got = append(got, fmt.Sprint(v))
+		}
+		assert.Equal(t, "", cmp.Diff([]string{"Tuple(true,[1 3 5])", "Tuple(false,[2 4])"}, got))
+		assert.Nil(t, c.Err())
+	})
+
+	t.Run("not hashable", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{1})
+		assert.Nil(t, err)
+		f, err := circle.NewMapper(func(x int) []int { return []int{x} })
+		assert.Nil(t, err)
+		exit, err := circle.NewGroupByExecutor(f, it).Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.True(t, errors.Is(err, circle.ErrNotHashable))
+	})
+}
+
+func TestPartitionExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		it, err := circle.NewIterator(nil)
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(int) bool { return true })
+		assert.Nil(t, err)
+		x, y, err := circle.NewPartitionExecutor(f, it).PartitionExecute()
+		assert.Nil(t, err)
+		{
+			_, err := x.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+		{
+			_, err := y.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		it, err := circle.NewIterator([]int{1, 2, 3, -1, 4})
+		assert.Nil(t, err)
+		f, err := circle.NewFilter(func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return x&1 == 1, nil
+		})
+		assert.Nil(t, err)
+		x, y, err := circle.NewPartitionExecutor(f, it).PartitionExecute()
+		assert.Nil(t, err)
+		for _, tc := range []struct {
+			it   circle.Iterator
+			want []int
+		}{
+			{it: y, want: []int{2}},
+			{it: x, want: []int{1, 3}},
+		} {
+			c := tc.it.Channel()
+			got := []int{}
+			for v := range c.C() {
+				got = append(got, v.(int))
+			}
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+			assert.Equal(t, errors.New("negative"), c.Err())
+		}
+	})
+}
+
+func TestChunkExecutor(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewChunkExecutor(0, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		ex, err := circle.NewChunkExecutor(2, circle.MustNewIterator(nil))
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("just", func(t *testing.T) {
+		ex, err := circle.NewChunkExecutor(2, circle.MustNewIterator([]int{1, 2, 3, 4}))
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		got := [][]interface{}{}
+		for v := range c.C() {
+			got = append(got, v.([]interface{}))
+		}
+		assert.Equal(t, "", cmp.Diff([][]interface{}{{1, 2}, {3, 4}}, got))
+		assert.Nil(t, c.Err())
+	})
+}
+
+func TestStepExecutor(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewStepExecutor(0, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	for _, tc := range []struct {
+		title string
+		n     int
+		src   []int
+		want  []int
+	}{
+		{
+			title: "nil",
+			n:     2,
+			want:  []int{},
+		},
+		{
+			title: "identity",
+			n:     1,
+			src:   []int{1, 2, 3},
+			want:  []int{1, 2, 3},
+		},
+		{
+			title: "step",
+			n:     3,
+			src:   []int{0, 1, 2, 3, 4, 5, 6},
+			want:  []int{0, 3, 6},
+		},
+		{
+			title: "larger than length",
+			n:     10,
+			src:   []int{0, 1, 2},
+			want:  []int{0},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			ex, err := circle.NewStepExecutor(tc.n, circle.MustNewIterator(tc.src))
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(exit)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+}
+
+func TestWindowExecutor(t *testing.T) {
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewWindowExecutor(-1, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	for _, tc := range []struct {
+		title string
+		size  int
+		src   []int
+		want  []string
+	}{
+		{
+			title: "short",
+			size:  3,
+			src:   []int{1, 2},
+			want:  []string{},
+		},
+		{
+			title: "just",
+			size:  2,
+			src:   []int{1, 2},
+			want:  []string{"Tuple(1,2)"},
+		},
+		{
+			title: "slide",
+			size:  3,
+			src:   []int{1, 2, 3, 4, 5},
+			want:  []string{"Tuple(1,2,3)", "Tuple(2,3,4)", "Tuple(3,4,5)"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			ex, err := circle.NewWindowExecutor(tc.size, circle.MustNewIterator(tc.src))
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			c := exit.Channel()
+			got := []string{}
+			for v := range c.C() {
+				got = append(got, fmt.Sprint(v))
+			}
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+			assert.Nil(t, c.Err())
+		})
+	}
+}
+
+func TestReverseExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		exit, err := circle.NewReverseExecutor(circle.MustNewIterator(nil)).Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var i int
+		it, err := circle.NewIterator(func() (interface{}, error) {
+			if i >= 2 {
+				return nil, errors.New("error")
+			}
+			i++
+			return i, nil
+		})
+		assert.Nil(t, err)
+		exit, err := circle.NewReverseExecutor(it).Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, errors.New("error"), err)
+	})
+}
+
+func TestAggregateWhileExecutor(t *testing.T) {
+	sum, err := circle.NewAggregator(func(acc, x int) int { return acc + x })
+	assert.Nil(t, err)
+	upTo10, err := circle.NewFilter(func(acc int) bool { return acc <= 10 })
+	assert.Nil(t, err)
+	naturals := func() circle.Iterator {
+		var i int
+		return circle.MustNewIterator(func() (interface{}, error) {
+			i++
+			return i, nil
+		})
+	}
+
+	t.Run("invalid aggregator", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(x int, acc string) string { return acc })
+		assert.Nil(t, err)
+		_, err = circle.NewAggregateWhileExecutor(f, upTo10, circle.MustNewIterator(nil), "")
+		assert.Equal(t, circle.ErrInvalidAggregateExecutor, err)
+	})
+
+	for _, tc := range []struct {
+		title   string
+		src     circle.Iterator
+		cond    circle.Filter
+		want    interface{}
+		wantErr error
+	}{
+		{
+			title: "nil",
+			src:   circle.MustNewIterator(nil),
+			cond:  upTo10,
+			want:  0,
+		},
+		{
+			title: "consume all",
+			src:   circle.MustNewIterator([]int{1, 2, 3}),
+			cond:  upTo10,
+			want:  6,
+		},
+		{
+			title: "stop infinite",
+			src:   naturals(),
+			cond:  upTo10,
+			want:  15,
+		},
+		{
+			title: "long stream",
+			src:   naturals(),
+			cond: func() circle.Filter {
+				f, err := circle.NewFilter(func(acc int) bool { return acc <= 5000000000 })
+				assert.Nil(t, err)
+				return f
+			}(),
+			want: 5000050000,
+		},
+		{
+			title: "cond error",
+			src:   naturals(),
+			cond: func() circle.Filter {
+				f, err := circle.NewFilter(func(acc int) (bool, error) {
+					if acc > 5 {
+						return false, errors.New("too large")
+					}
+					return true, nil
+				})
+				assert.Nil(t, err)
+				return f
+			}(),
+			wantErr: errors.New("too large"),
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			ex, err := circle.NewAggregateWhileExecutor(sum, tc.cond, tc.src, 0)
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			got, err := exit.Next()
+			if tc.wantErr != nil {
+				assert.Equal(t, tc.wantErr.Error(), err.Error())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+			_, err = exit.Next()
+			assert.Equal(t, circle.ErrEOI, err)
+		})
+	}
+}
+
+func TestScanExecutor(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(acc, x int) int { return acc + x })
+		assert.Nil(t, err)
+		ex, err := circle.NewScanExecutor(f, circle.MustNewIterator(nil), 0)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(acc string, x int) (string, error) {
+			if x < 0 {
+				return "", errors.New("negative")
+			}
+			return fmt.Sprintf("%s+%d", acc, x), nil
+		})
+		assert.Nil(t, err)
+		ex, err := circle.NewScanExecutor(f, circle.MustNewIterator([]int{1, 2, -1, 3}), "0")
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		got := []string{}
+		for v := range c.C() {
+			got = append(got, v.(string))
+		}
+		assert.Equal(t, "", cmp.Diff([]string{"0+1", "0+1+2"}, got))
+		assert.Equal(t, errors.New("negative"), c.Err())
+	})
+}
+
+func TestWindowAggregateExecutor(t *testing.T) {
+	sum, err := circle.NewAggregator(func(acc, x int) int { return acc + x })
+	assert.Nil(t, err)
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewWindowAggregateExecutor(0, sum, circle.MustNewIterator(nil), 0)
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(acc string, x int) string { return acc })
+		assert.Nil(t, err)
+		_, err = circle.NewWindowAggregateExecutor(2, f, circle.MustNewIterator(nil), "",
+			circle.WithAggregateExecutorType(circle.RAggregateExecutorType))
+		assert.Equal(t, circle.ErrInvalidAggregateExecutor, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		ex, err := circle.NewWindowAggregateExecutor(2, sum, circle.MustNewIterator(nil), 0)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		_, err = exit.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		ex, err := circle.NewWindowAggregateExecutor(2, sum, circle.MustNewIterator([]int{1, 2, 3, 4, 5}), 10)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{13, 17, 15}, got))
+	})
+
+	t.Run("apply error", func(t *testing.T) {
+		f, err := circle.NewAggregator(func(acc, x int) (int, error) {
+			if x < 0 {
+				return 0, errors.New("negative")
+			}
+			return acc + x, nil
+		})
+		assert.Nil(t, err)
+		ex, err := circle.NewWindowAggregateExecutor(2, f, circle.MustNewIterator([]int{1, 2, -1, 3}), 0)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		c := exit.Channel()
+		got := []int{}
+		for v := range c.C() {
+			got = append(got, v.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{3}, got))
+		assert.Equal(t, errors.New("negative"), c.Err())
+	})
+}
+
+func TestParallelMapExecutor(t *testing.T) {
+	t.Run("invalid workers", func(t *testing.T) {
+		f, err := circle.NewMapper(func(x int) int { return x })
+		assert.Nil(t, err)
+		_, err = circle.NewParallelMapExecutor(f, circle.MustNewIterator(nil), 0)
+		assert.Equal(t, circle.ErrInvalidWorkers, err)
+	})
+
+	newMapper := func(t *testing.T) circle.Mapper {
+		f, err := circle.NewMapper(func(x int) (int, error) {
+			if x < 0 {
+				return 0, errors.New("negative")
+			}
+			time.Sleep(time.Duration(x%3) * time.Millisecond)
+			return x * 10, nil
+		})
+		assert.Nil(t, err)
+		return f
+	}
+	src := []int{1, 2, 3, -1, 4, 5, 6, 7, 8, 9}
+	want := []int{10, 20, 30, 40, 50, 60, 70, 80, 90}
+
+	t.Run("ordered", func(t *testing.T) {
+		ex, err := circle.NewParallelMapExecutor(newMapper(t), circle.MustNewIterator(src), 4)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff(want, got))
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		ex, err := circle.NewParallelMapExecutor(newMapper(t), circle.MustNewIterator(src), 4,
+			circle.WithParallelMapExecutorOrder(false))
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, circle.ErrEOI, err)
+		sort.Ints(got)
+		assert.Equal(t, "", cmp.Diff(want, got))
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		e := errors.New("source")
+		var i int
+		it, err := circle.NewIterator(func() (interface{}, error) {
+			if i >= 3 {
+				return nil, e
+			}
+			i++
+			return i, nil
+		})
+		assert.Nil(t, err)
+		ex, err := circle.NewParallelMapExecutor(newMapper(t), it, 2)
+		assert.Nil(t, err)
+		exit, err := ex.Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(exit)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{10, 20, 30}, got))
+	})
+}
+
+func TestTopNExecutor(t *testing.T) {
+	less, err := circle.NewComparator(func(x, y int) bool { return x < y })
+	assert.Nil(t, err)
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := circle.NewTopNExecutor(0, less, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+		_, err = circle.NewBottomNExecutor(-1, less, circle.MustNewIterator(nil))
+		assert.Equal(t, circle.ErrInvalidSize, err)
+	})
+
+	for _, tc := range []struct {
+		title    string
+		n        int
+		src      []int
+		isBottom bool
+		want     []int
+	}{
+		{
+			title: "top nil",
+			n:     3,
+			want:  []int{},
+		},
+		{
+			title: "top",
+			n:     3,
+			src:   []int{5, 1, 9, 3, 7, 2, 8},
+			want:  []int{9, 8, 7},
+		},
+		{
+			title: "top fewer than n",
+			n:     5,
+			src:   []int{2, 3, 1},
+			want:  []int{3, 2, 1},
+		},
+		{
+			title:    "bottom",
+			n:        3,
+			src:      []int{5, 1, 9, 3, 7, 2, 8},
+			isBottom: true,
+			want:     []int{1, 2, 3},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			newExecutor := circle.NewTopNExecutor
+			if tc.isBottom {
+				newExecutor = circle.NewBottomNExecutor
+			}
+			ex, err := newExecutor(tc.n, less, circle.MustNewIterator(tc.src))
+			assert.Nil(t, err)
+			exit, err := ex.Execute()
+			assert.Nil(t, err)
+			got, err := iteratorToInts(exit)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+}
@@ -19,7 +19,8 @@ type (
 	}
 
 	mapper struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -45,7 +46,8 @@ func NewMapper(f interface{}) (Mapper, error) {
 		return nil, ErrInvalidMapper
 	}
 	return &mapper{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -56,12 +58,12 @@ func (s *mapper) Apply(v interface{}) (ret interface{}, rerr error) {
 			rerr = fmt.Errorf("%w %s", ErrApply, err)
 		}
 	}()
-	av, err := reflection.Convert(v, reflect.TypeOf(s.f).In(0), true)
+	av, err := reflection.Convert(v, s.ft.In(0), true)
 	if err != nil {
 		return nil, err
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call([]reflect.Value{av})
+		r  = s.fv.Call([]reflect.Value{av})
 		r0 = r[0].Interface()
 	)
 	if len(r) == 2 {
@@ -73,6 +75,139 @@ func (s *mapper) Apply(v interface{}) (ret interface{}, rerr error) {
 	return r0, nil
 }
 
+type (
+	// BiMapper is a func(A, B) (C, error) or func(A, B) C.
+	BiMapper interface {
+		Apply(x, y interface{}) (interface{}, error)
+	}
+
+	biMapper struct {
+		ft reflect.Type
+		fv reflect.Value
+	}
+)
+
+func isBiMapper(f interface{}) bool {
+	t := reflect.TypeOf(f)
+	if !(t.Kind() == reflect.Func && t.NumIn() == 2) {
+		return false
+	}
+	switch t.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return t.Out(1).String() == "error"
+	default:
+		return false
+	}
+}
+
+// NewBiMapper returns a new BiMapper.
+// If f is not appropriate for BiMapper, returns ErrInvalidMapper.
+func NewBiMapper(f interface{}) (BiMapper, error) {
+	if !isBiMapper(f) {
+		return nil, ErrInvalidMapper
+	}
+	return &biMapper{
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
+	}, nil
+}
+
+func (s *biMapper) Apply(x, y interface{}) (ret interface{}, rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			ret = nil
+			rerr = fmt.Errorf("%w %s", ErrApply, err)
+		}
+	}()
+	t := s.ft
+	vx, err := reflection.Convert(x, t.In(0), true)
+	if err != nil {
+		return nil, err
+	}
+	vy, err := reflection.Convert(y, t.In(1), true)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		r  = s.fv.Call([]reflect.Value{vx, vy})
+		r0 = r[0].Interface()
+	)
+	if len(r) == 2 {
+		r1 := r[1].Interface()
+		if err, ok := r1.(error); ok {
+			return r0, err
+		}
+	}
+	return r0, nil
+}
+
+type (
+	// KeyValueMapper is a func(A) (K, V, error) or func(A) (K, V).
+	KeyValueMapper interface {
+		Apply(v interface{}) (key, value interface{}, err error)
+	}
+
+	keyValueMapper struct {
+		ft reflect.Type
+		fv reflect.Value
+	}
+)
+
+func isKeyValueMapper(f interface{}) bool {
+	t := reflect.TypeOf(f)
+	if !(t.Kind() == reflect.Func && t.NumIn() == 1) {
+		return false
+	}
+	switch t.NumOut() {
+	case 2:
+		return true
+	case 3:
+		return t.Out(2).String() == "error"
+	default:
+		return false
+	}
+}
+
+// NewKeyValueMapper returns a new KeyValueMapper.
+// If f is not appropriate for KeyValueMapper, returns ErrInvalidMapper.
+func NewKeyValueMapper(f interface{}) (KeyValueMapper, error) {
+	if !isKeyValueMapper(f) {
+		return nil, ErrInvalidMapper
+	}
+	return &keyValueMapper{
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
+	}, nil
+}
+
+func (s *keyValueMapper) Apply(v interface{}) (key, value interface{}, rerr error) {
+	defer func() {
+		if err := recover(); err != nil {
+			key = nil
+			value = nil
+			rerr = fmt.Errorf("%w %s", ErrApply, err)
+		}
+	}()
+	av, err := reflection.Convert(v, s.ft.In(0), true)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		r  = s.fv.Call([]reflect.Value{av})
+		r0 = r[0].Interface()
+		r1 = r[1].Interface()
+	)
+	if len(r) == 3 {
+		r2 := r[2].Interface()
+		if err, ok := r2.(error); ok {
+			return r0, r1, err
+		}
+	}
+	return r0, r1, nil
+}
+
 var (
 	ErrInvalidFilter = errors.New("invalid filter")
 )
@@ -84,7 +219,8 @@ type (
 	}
 
 	filter struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -110,7 +246,8 @@ func NewFilter(f interface{}) (Filter, error) {
 		return nil, ErrInvalidFilter
 	}
 	return &filter{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -121,12 +258,12 @@ func (s *filter) Apply(v interface{}) (ret bool, rerr error) {
 			rerr = fmt.Errorf("%w %s", ErrApply, err)
 		}
 	}()
-	av, err := reflection.Convert(v, reflect.TypeOf(s.f).In(0), true)
+	av, err := reflection.Convert(v, s.ft.In(0), true)
 	if err != nil {
 		return false, err
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call([]reflect.Value{av})
+		r  = s.fv.Call([]reflect.Value{av})
 		r0 = r[0].Bool()
 	)
 	if len(r) == 2 {
@@ -152,8 +289,9 @@ type (
 	AggregatorType int
 
 	aggregator struct {
-		f interface{}
-		t AggregatorType
+		ft reflect.Type
+		fv reflect.Value
+		t  AggregatorType
 	}
 )
 
@@ -218,8 +356,9 @@ func NewAggregator(f interface{}) (Aggregator, error) {
 		return nil, ErrInvalidAggregator
 	}
 	return &aggregator{
-		f: f,
-		t: t,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
+		t:  t,
 	}, nil
 }
 
@@ -232,7 +371,7 @@ func (s *aggregator) Apply(x, y interface{}) (ret interface{}, rerr error) {
 			rerr = fmt.Errorf("%w %s", ErrApply, err)
 		}
 	}()
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	vx, err := reflection.Convert(x, t.In(0), true)
 	if err != nil {
 		return nil, err
@@ -242,7 +381,7 @@ func (s *aggregator) Apply(x, y interface{}) (ret interface{}, rerr error) {
 		return nil, err
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call([]reflect.Value{vx, vy})
+		r  = s.fv.Call([]reflect.Value{vx, vy})
 		r0 = r[0].Interface()
 	)
 	if len(r) == 2 {
@@ -265,7 +404,8 @@ type (
 	}
 
 	comparator struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -292,7 +432,8 @@ func NewComparator(f interface{}) (Comparator, error) {
 		return nil, ErrInvalidComparator
 	}
 	return &comparator{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -303,7 +444,7 @@ func (s *comparator) Apply(x, y interface{}) (ret bool, rerr error) {
 			rerr = fmt.Errorf("%w %s", ErrApply, err)
 		}
 	}()
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	vx, err := reflection.Convert(x, t.In(0), true)
 	if err != nil {
 		return false, err
@@ -313,7 +454,7 @@ func (s *comparator) Apply(x, y interface{}) (ret bool, rerr error) {
 		return false, err
 	}
 	var (
-		r  = reflect.ValueOf(s.f).Call([]reflect.Value{vx, vy})
+		r  = s.fv.Call([]reflect.Value{vx, vy})
 		r0 = r[0].Bool()
 	)
 	if len(r) == 2 {
@@ -325,6 +466,55 @@ func (s *comparator) Apply(x, y interface{}) (ret bool, rerr error) {
 	return r0, nil
 }
 
+type (
+	numberComparator struct {
+		isDesc bool
+	}
+	stringComparator struct{}
+)
+
+// AscComparator returns a new Comparator that reports whether x is less than y as numbers.
+//
+// Each argument is converted to float64, if fails, returns ErrApply.
+func AscComparator() Comparator { return &numberComparator{} }
+
+// DescComparator returns a new Comparator that reports whether x is greater than y as numbers.
+//
+// Each argument is converted to float64, if fails, returns ErrApply.
+func DescComparator() Comparator { return &numberComparator{isDesc: true} }
+
+func (s *numberComparator) Apply(x, y interface{}) (bool, error) {
+	vx, err := reflection.Convert(x, float64Type, false)
+	if err != nil {
+		return false, fmt.Errorf("%w %v", ErrApply, err)
+	}
+	vy, err := reflection.Convert(y, float64Type, false)
+	if err != nil {
+		return false, fmt.Errorf("%w %v", ErrApply, err)
+	}
+	if s.isDesc {
+		return vx.Float() > vy.Float(), nil
+	}
+	return vx.Float() < vy.Float(), nil
+}
+
+// StringComparator returns a new Comparator that reports whether x is less than y lexicographically.
+//
+// If an argument is not a string, returns ErrApply.
+func StringComparator() Comparator { return &stringComparator{} }
+
+func (*stringComparator) Apply(x, y interface{}) (bool, error) {
+	sx, ok := x.(string)
+	if !ok {
+		return false, fmt.Errorf("%w %T is not a string", ErrApply, x)
+	}
+	sy, ok := y.(string)
+	if !ok {
+		return false, fmt.Errorf("%w %T is not a string", ErrApply, y)
+	}
+	return sx < sy, nil
+}
+
 var (
 	ErrInvalidConsumer = errors.New("invalid consumer")
 )
@@ -335,7 +525,8 @@ type (
 		Apply(x interface{}) error
 	}
 	consumer struct {
-		f interface{}
+		ft reflect.Type
+		fv reflect.Value
 	}
 )
 
@@ -360,7 +551,8 @@ func NewConsumer(f interface{}) (Consumer, error) {
 		return nil, ErrInvalidConsumer
 	}
 	return &consumer{
-		f: f,
+		ft: reflect.TypeOf(f),
+		fv: reflect.ValueOf(f),
 	}, nil
 }
 
@@ -370,13 +562,13 @@ func (s *consumer) Apply(x interface{}) (rerr error) {
 			rerr = fmt.Errorf("%w %s", ErrApply, err)
 		}
 	}()
-	t := reflect.TypeOf(s.f)
+	t := s.ft
 	vx, err := reflection.Convert(x, t.In(0), true)
 	if err != nil {
 		return err
 	}
 	var (
-		r = reflect.ValueOf(s.f).Call([]reflect.Value{vx})
+		r = s.fv.Call([]reflect.Value{vx})
 	)
 	if len(r) == 1 {
 		r0 := r[0].Interface()
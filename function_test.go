@@ -35,7 +35,7 @@ func ExampleMapper() {
 	// false out of range
 }
 
-func ExampleMapperWithoutError() {
+func ExampleMapper_withoutError() {
 	f, err := circle.NewMapper(func(x int) bool {
 		return x > 0
 	})
@@ -115,6 +115,78 @@ func testMapperApply(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, "", cmp.Diff([]int{1, 5, 15}, v))
 		},
+		"interface": func(t *testing.T) {
+			f, err := circle.NewMapper(func(x fmt.Stringer) string { return x.String() })
+			assert.Nil(t, err)
+			v, err := f.Apply(circle.NewJust(1))
+			assert.Nil(t, err)
+			assert.Equal(t, "Just(1)", v)
+			_, err = f.Apply(1)
+			assert.NotNil(t, err)
+			assert.True(t, strings.Contains(err.Error(), "int does not implement fmt.Stringer"), err.Error())
+		},
+		"interface slice": func(t *testing.T) {
+			f, err := circle.NewMapper(func(xs []fmt.Stringer) int { return len(xs) })
+			assert.Nil(t, err)
+			v, err := f.Apply([]interface{}{circle.NewJust(1), circle.NewTuple()})
+			assert.Nil(t, err)
+			assert.Equal(t, 2, v)
+		},
+		"nil interface": func(t *testing.T) {
+			f, err := circle.NewMapper(func(err error) bool { return err == nil })
+			assert.Nil(t, err)
+			v, err := f.Apply(nil)
+			assert.Nil(t, err)
+			assert.Equal(t, true, v)
+		},
+	} {
+		t.Run(name, tc)
+	}
+}
+
+func TestFilterInterface(t *testing.T) {
+	f, err := circle.NewFilter(func(x fmt.Stringer) bool { return strings.HasPrefix(x.String(), "Just") })
+	assert.Nil(t, err)
+	for _, tc := range []struct {
+		arg  interface{}
+		want bool
+	}{
+		{arg: circle.NewJust(1), want: true},
+		{arg: circle.NewNothing(), want: false},
+	} {
+		got, err := f.Apply(tc.arg)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.want, got, tc.arg)
+	}
+	_, err = f.Apply("Just")
+	assert.NotNil(t, err)
+}
+
+func TestKeyValueMapper(t *testing.T) {
+	for name, tc := range map[string]func(t *testing.T){
+		"invalid": func(t *testing.T) {
+			_, err := circle.NewKeyValueMapper(strings.ToUpper)
+			assert.Equal(t, circle.ErrInvalidMapper, err)
+		},
+		"without error": func(t *testing.T) {
+			f, err := circle.NewKeyValueMapper(func(x int) (string, int) {
+				return strconv.Itoa(x), x * x
+			})
+			assert.Nil(t, err)
+			k, v, err := f.Apply(3)
+			assert.Nil(t, err)
+			assert.Equal(t, "3", k)
+			assert.Equal(t, 9, v)
+		},
+		"error": func(t *testing.T) {
+			e := errors.New("error")
+			f, err := circle.NewKeyValueMapper(func(x int) (string, int, error) {
+				return "", 0, e
+			})
+			assert.Nil(t, err)
+			_, _, err = f.Apply(3)
+			assert.Equal(t, e, err)
+		},
 	} {
 		t.Run(name, tc)
 	}
@@ -330,6 +402,86 @@ func testComparatorApply(t *testing.T) {
 	})
 }
 
+func TestBuiltinComparator(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		f       circle.Comparator
+		x       interface{}
+		y       interface{}
+		want    bool
+		isError bool
+	}{
+		{
+			title: "asc",
+			f:     circle.AscComparator(),
+			x:     1,
+			y:     2.5,
+			want:  true,
+		},
+		{
+			title: "asc equal",
+			f:     circle.AscComparator(),
+			x:     uint8(2),
+			y:     2.0,
+		},
+		{
+			title: "desc",
+			f:     circle.DescComparator(),
+			x:     int64(3),
+			y:     float32(2),
+			want:  true,
+		},
+		{
+			title:   "not number",
+			f:       circle.AscComparator(),
+			x:       1,
+			y:       "2",
+			isError: true,
+		},
+		{
+			title:   "nil",
+			f:       circle.DescComparator(),
+			y:       1,
+			isError: true,
+		},
+		{
+			title: "string",
+			f:     circle.StringComparator(),
+			x:     "a",
+			y:     "b",
+			want:  true,
+		},
+		{
+			title:   "not string",
+			f:       circle.StringComparator(),
+			x:       "a",
+			y:       1,
+			isError: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := tc.f.Apply(tc.x, tc.y)
+			if tc.isError {
+				assert.True(t, errors.Is(err, circle.ErrApply))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("sort", func(t *testing.T) {
+		it, err := circle.NewStream(circle.MustNewIterator([]interface{}{3, 1.5, uint(2)})).
+			Sort(circle.DescComparator()).
+			Execute()
+		assert.Nil(t, err)
+		got, err := circle.Collect(it)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{3, uint(2), 1.5}, got))
+	})
+}
+
 func TestConsumer(t *testing.T) {
 	for name, tc := range map[string]func(*testing.T){
 		"invalid": testInvalidConsumer,
@@ -382,3 +534,45 @@ func testConsumerApply(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkMapperApply(b *testing.B) {
+	f, err := circle.NewMapper(func(x int) (int, error) { return x + 1, nil })
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Apply(i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFilterApply(b *testing.B) {
+	f, err := circle.NewFilter(func(x int) bool { return x&1 == 0 })
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Apply(i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkComparatorApply(b *testing.B) {
+	f, err := circle.NewComparator(func(x, y int) bool { return x < y })
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Apply(i, i+1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
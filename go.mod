@@ -1,8 +1,14 @@
 module github.com/berquerant/circle
 
-go 1.14
+go 1.18
 
 require (
 	github.com/google/go-cmp v0.5.0
 	github.com/stretchr/testify v1.6.1
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -18,9 +18,26 @@ func Convert(v interface{}, t reflect.Type, isShallow bool) (reflect.Value, erro
 }
 
 func convert(v interface{}, t reflect.Type, isShallow bool) (reflect.Value, error) {
+	if x, ok := exactValueOf(v, t); ok {
+		return x, nil
+	}
 	return newConverter(v, t, isShallow).convert()
 }
 
+// exactValueOf returns the value as it is if the type of the value is t
+// and t is not a container, that is converted into a new value.
+func exactValueOf(v interface{}, t reflect.Type) (reflect.Value, bool) {
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Chan, reflect.Map:
+		return reflect.Value{}, false
+	}
+	x := reflect.ValueOf(v)
+	if !x.IsValid() || x.Type() != t {
+		return reflect.Value{}, false
+	}
+	return x, true
+}
+
 type (
 	converter struct {
 		v         interface{}
@@ -53,6 +70,8 @@ func (s *converter) convert() (ret reflect.Value, err error) {
 		return s.convertChan()
 	case reflect.Map:
 		return s.convertMap()
+	case reflect.Interface:
+		return s.convertInterface()
 	default:
 		if s.isShallow {
 			return s.valueOf(), nil
@@ -63,6 +82,19 @@ func (s *converter) convert() (ret reflect.Value, err error) {
 
 func (s *converter) valueOf() reflect.Value { return reflect.ValueOf(s.v) }
 
+// convertInterface returns the value as it is if the value implements the interface.
+// A nil value is converted into the nil interface.
+func (s *converter) convertInterface() (reflect.Value, error) {
+	sv := s.valueOf()
+	if !sv.IsValid() {
+		return reflect.Zero(s.t), nil
+	}
+	if !sv.Type().Implements(s.t) {
+		return reflect.Zero(s.t), fmt.Errorf("%w %s does not implement %s", ErrCannotConvert, sv.Type(), s.t)
+	}
+	return sv, nil
+}
+
 func (s *converter) convertChan() (reflect.Value, error) {
 	return reflect.MakeChan(s.t, s.valueOf().Cap()), nil
 }
@@ -1,9 +1,14 @@
 package circle
 
 import (
+	"bufio"
+	"container/heap"
 	"context"
+	"database/sql"
 	"errors"
+	"io"
 	"reflect"
+	"sync"
 
 	"github.com/berquerant/circle/internal/atomic"
 )
@@ -32,10 +37,22 @@ type (
 		// ChannelWithContext converts the iterator to IteratorChannel.
 		// If context canceled, the channel closes.
 		ChannelWithContext(ctx context.Context) IteratorChannel
+		// Drain consumes the iterator until the end and discards all elements.
+		//
+		// This returns the error that terminated the iteration except ErrEOI.
+		Drain() error
+		// Count consumes the iterator until the end and returns the number of the elements.
+		//
+		// This returns the error that terminated the iteration except ErrEOI
+		// with the number of the elements before the error.
+		Count() (int, error)
 	}
 	iterator struct {
 		isEOI bool
 		f     IteratorFunc
+		// src is the source of this if isReiterable.
+		src          interface{}
+		isReiterable bool
 	}
 	// IteratorFunc is an iterator as a function.
 	IteratorFunc func() (interface{}, error)
@@ -59,7 +76,53 @@ func NewIterator(v interface{}) (Iterator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newIterator(f), nil
+	it := &iterator{
+		f: f,
+	}
+	if isReiterable(v) {
+		it.src = v
+		it.isReiterable = true
+	}
+	return it, nil
+}
+
+// isReiterable returns true if NewIterator(v) can create an iterator that yields the same elements again.
+func isReiterable(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// reiterate returns a new iterator that yields the elements of the source of it again.
+// If the source of it is not a slice, an array or a map, returns false.
+func reiterate(it Iterator) (Iterator, bool) {
+	x, ok := it.(*iterator)
+	if !ok || !x.isReiterable {
+		return nil, false
+	}
+	return MustNewIterator(x.src), true
+}
+
+// NewIteratorWithContext returns a new Iterator like NewIterator().
+//
+// If v is a receivable channel, the iterator stops waiting for the channel
+// and ends with ErrEOI when ctx is canceled.
+// Otherwise, ctx is ignored.
+func NewIteratorWithContext(ctx context.Context, v interface{}) (Iterator, error) {
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.Chan {
+		f, err := newChanIteratorFuncWithContext(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return newIterator(f), nil
+	}
+	return NewIterator(v)
 }
 
 // MustNewIterator returns a new Iterator.
@@ -74,6 +137,158 @@ func MustNewIterator(v interface{}) Iterator {
 	return it
 }
 
+// NewLineIterator returns a new Iterator that yields each line of r as a string.
+//
+// Lines are split by bufio.ScanLines.
+// If r causes error except io.EOF, the iterator yields the error.
+// A read blocked on r cannot be interrupted, even by the context of ChannelWithContext(),
+// see NewLineIteratorWithContext().
+func NewLineIterator(r io.Reader) (Iterator, error) {
+	sc := bufio.NewScanner(r)
+	return newIterator(func() (interface{}, error) {
+		if sc.Scan() {
+			return sc.Text(), nil
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrEOI
+	}), nil
+}
+
+// LineIterator is an Iterator that reads the lines on another goroutine.
+type LineIterator interface {
+	Iterator
+	// Close stops reading and releases the goroutine.
+	// Call this when abandoning the iterator before it ends,
+	// otherwise the goroutine leaks unless the context is canceled.
+	// The iterator yields ErrEOI after this call.
+	Close()
+}
+
+// NewLineIteratorWithContext returns a new Iterator that yields each line of r as a string like NewLineIterator().
+//
+// r is read on another goroutine, so the iterator stops waiting for r and ends with ErrEOI
+// when ctx is canceled or the iterator is closed even if the read is blocked.
+// Cancel ctx or call Close() to release the goroutine if you stop reading the iterator before the end.
+// A blocked read cannot be interrupted, the goroutine exits after it returns.
+func NewLineIteratorWithContext(ctx context.Context, r io.Reader) (LineIterator, error) {
+	type line struct {
+		text string
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var (
+		sc = bufio.NewScanner(r)
+		c  chan line
+	)
+	scan := func() {
+		defer close(c)
+		for sc.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- line{text: sc.Text()}:
+			}
+		}
+		if err := sc.Err(); err != nil {
+			select {
+			case <-ctx.Done():
+			case c <- line{err: err}:
+			}
+		}
+	}
+	it := newIterator(func() (interface{}, error) {
+		if ctx.Err() != nil {
+			// do not start reading after closed
+			return nil, ErrEOI
+		}
+		if c == nil {
+			// start reading on the first call
+			c = make(chan line)
+			go scan()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ErrEOI
+		case x, ok := <-c:
+			if !ok {
+				return nil, ErrEOI
+			}
+			if x.err != nil {
+				return nil, x.err
+			}
+			return x.text, nil
+		}
+	})
+	return &cancelIterator{
+		Iterator: it,
+		cancel:   cancel,
+	}, nil
+}
+
+// NewRowsIterator returns a new Iterator that yields a value scanned from each row of rows by scan.
+//
+// If rows.Next() returns false, the iterator yields rows.Err() if it is not nil, else ErrEOI.
+// If scan returns error, the iterator yields the error.
+// rows is closed when the iteration ends.
+// If rows or scan is nil, returns ErrCannotCreateIterator.
+func NewRowsIterator(rows *sql.Rows, scan func(*sql.Rows) (interface{}, error)) (Iterator, error) {
+	if rows == nil || scan == nil {
+		return nil, ErrCannotCreateIterator
+	}
+	return newIterator(func() (interface{}, error) {
+		if !rows.Next() {
+			err := rows.Err()
+			_ = rows.Close()
+			if err != nil {
+				return nil, err
+			}
+			return nil, ErrEOI
+		}
+		v, err := scan(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		return v, nil
+	}), nil
+}
+
+// NewStringIterator returns a new Iterator that yields each rune of s.
+//
+// NewIterator() yields a string itself, this iterates on the runes of the string.
+func NewStringIterator(s string) (Iterator, error) {
+	return NewIterator([]rune(s))
+}
+
+// NewRangeIterator returns a new Iterator that yields integers from start to stop (exclusive) by step.
+//
+// If step is negative, yields decreasing integers while greater than stop.
+// If step is zero, returns ErrCannotCreateIterator.
+func NewRangeIterator(start, stop, step int) (Iterator, error) {
+	if step == 0 {
+		return nil, ErrCannotCreateIterator
+	}
+	var (
+		i     = start
+		isEOI bool
+	)
+	return newIterator(func() (interface{}, error) {
+		if isEOI || (step > 0 && i >= stop) || (step < 0 && i <= stop) {
+			return nil, ErrEOI
+		}
+		x := i
+		// the next value passes stop, stop here to avoid overflow
+		if (step > 0 && i > stop-step) || (step < 0 && i < stop-step) {
+			isEOI = true
+		} else {
+			i += step
+		}
+		return x, nil
+	}), nil
+}
+
 func newIterator(f IteratorFunc) Iterator {
 	return &iterator{
 		f: f,
@@ -92,6 +307,35 @@ func (s *iterator) Next() (interface{}, error) {
 	return v, nil
 }
 
+func (s *iterator) Drain() error        { return drain(s) }
+func (s *iterator) Count() (int, error) { return count(s) }
+
+func drain(it Iterator) error {
+	for {
+		_, err := it.Next()
+		if err == ErrEOI {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func count(it Iterator) (int, error) {
+	var n int
+	for {
+		_, err := it.Next()
+		if err == ErrEOI {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
 func (s *iterator) Channel() IteratorChannel                               { return s.channel(context.Background()) }
 func (s *iterator) ChannelWithContext(ctx context.Context) IteratorChannel { return s.channel(ctx) }
 func (s *iterator) channel(ctx context.Context) IteratorChannel            { return newIteratorChannel(ctx, s) }
@@ -104,27 +348,34 @@ type (
 		C() <-chan interface{}
 		// Err returns the first non-EOI error that was encountered by the iteration.
 		Err() error
+		// Close stops the iteration and closes the channel.
+		// Call this when abandoning the channel before it closes,
+		// otherwise the goroutine that sends to the channel leaks unless the context is canceled.
+		// The channel may yield some elements that were read before this call.
+		Close()
 	}
 	iteratorChannel struct {
 		iter     Iterator
 		c        chan interface{}
 		err      error
 		isClosed *atomic.Bool
+		cancel   context.CancelFunc
 	}
 )
 
 func newIteratorChannel(ctx context.Context, iter Iterator) IteratorChannel {
+	ctx, cancel := context.WithCancel(ctx)
 	s := &iteratorChannel{
 		iter:     iter,
 		c:        make(chan interface{}),
 		isClosed: atomic.NewBool(false),
+		cancel:   cancel,
 	}
 	go s.iterate(ctx)
 	return s
 }
 
 func (s *iteratorChannel) iterate(ctx context.Context) {
-	ctx, cancel := context.WithCancel(ctx)
 	go func() {
 		<-ctx.Done()
 		s.isClosed.Set(true)
@@ -133,7 +384,7 @@ func (s *iteratorChannel) iterate(ctx context.Context) {
 	}()
 
 	defer func() {
-		cancel()
+		s.cancel()
 		close(s.c)
 	}()
 
@@ -154,6 +405,529 @@ func (s *iteratorChannel) iterate(ctx context.Context) {
 
 func (s *iteratorChannel) C() <-chan interface{} { return s.c }
 func (s *iteratorChannel) Err() error            { return s.err }
+func (s *iteratorChannel) Close()                { s.cancel() }
+
+type contextIterator struct {
+	Iterator
+	ctx context.Context
+}
+
+// withContextIterator returns a new iterator that yields ctx.Err() after ctx is canceled
+// and its Channel() honors ctx.
+// If ctx is never canceled, returns it.
+func withContextIterator(ctx context.Context, it Iterator) Iterator {
+	if ctx.Done() == nil {
+		return it
+	}
+	return &contextIterator{
+		Iterator: newIterator(func() (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return it.Next()
+		}),
+		ctx: ctx,
+	}
+}
+
+func (s *contextIterator) Channel() IteratorChannel { return s.Iterator.ChannelWithContext(s.ctx) }
+
+type (
+	// ErrorCollector provides the errors that were collected instead of stopping the iteration.
+	// The iterator and its IteratorChannel from Stream with WithCollectErrors() implement this.
+	ErrorCollector interface {
+		// Errors returns the collected errors in the order of occurrence.
+		Errors() []error
+	}
+
+	errorList struct {
+		mux  sync.Mutex
+		errs []error
+	}
+
+	errorCollectorIterator struct {
+		Iterator
+		errs *errorList
+	}
+
+	errorCollectorIteratorChannel struct {
+		IteratorChannel
+		errs *errorList
+	}
+)
+
+func (s *errorList) add(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+func (s *errorList) Errors() []error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	errs := make([]error, len(s.errs))
+	copy(errs, s.errs)
+	return errs
+}
+
+func (s *errorCollectorIterator) Errors() []error { return s.errs.Errors() }
+func (s *errorCollectorIterator) Channel() IteratorChannel {
+	return &errorCollectorIteratorChannel{
+		IteratorChannel: s.Iterator.Channel(),
+		errs:            s.errs,
+	}
+}
+func (s *errorCollectorIterator) ChannelWithContext(ctx context.Context) IteratorChannel {
+	return &errorCollectorIteratorChannel{
+		IteratorChannel: s.Iterator.ChannelWithContext(ctx),
+		errs:            s.errs,
+	}
+}
+
+func (s *errorCollectorIteratorChannel) Errors() []error { return s.errs.Errors() }
+
+type (
+	// BufferedIterator is an Iterator that prefetches the elements in the background.
+	BufferedIterator interface {
+		Iterator
+		// Close stops prefetching and releases the background goroutine.
+		// Call this when abandoning the iterator before it ends,
+		// otherwise the goroutine leaks unless the context is canceled.
+		// The iterator yields ErrEOI after this call.
+		Close()
+	}
+
+	cancelIterator struct {
+		Iterator
+		cancel context.CancelFunc
+	}
+
+	bufferedElement struct {
+		v   interface{}
+		err error
+	}
+)
+
+func (s *cancelIterator) Close() { s.cancel() }
+
+// NewBufferedIterator returns a new iterator that prefetches up to size elements of it in the background.
+// See NewBufferedIteratorWithContext.
+func NewBufferedIterator(it Iterator, size int) BufferedIterator {
+	return NewBufferedIteratorWithContext(context.Background(), it, size)
+}
+
+// NewBufferedIteratorWithContext returns a new iterator that prefetches up to size elements of it
+// in the background.
+// The iterator yields the buffered elements and then the error that terminated it.
+// The background goroutine stops when it ends, ctx is canceled or the iterator is closed,
+// cancel ctx or call Close() to release the goroutine if you stop reading the iterator before the end.
+// A call of it.Next() in progress cannot be interrupted, the goroutine exits after it returns.
+// The iterator yields ctx.Err() after ctx is canceled.
+func NewBufferedIteratorWithContext(ctx context.Context, it Iterator, size int) BufferedIterator {
+	if size < 0 {
+		size = 0
+	}
+	var (
+		c            = make(chan *bufferedElement, size)
+		bctx, cancel = context.WithCancel(ctx)
+	)
+	go func() {
+		defer close(c)
+		for {
+			v, err := it.Next()
+			select {
+			case <-bctx.Done():
+				return
+			case c <- &bufferedElement{v: v, err: err}:
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	// closedErr returns the error after ctx is canceled or the iterator is closed.
+	closedErr := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ErrEOI
+	}
+	return &cancelIterator{
+		Iterator: newIterator(func() (interface{}, error) {
+			if bctx.Err() != nil {
+				return nil, closedErr()
+			}
+			select {
+			case <-bctx.Done():
+				return nil, closedErr()
+			case x, ok := <-c:
+				if !ok {
+					return nil, ErrEOI
+				}
+				if x.err != nil {
+					return nil, x.err
+				}
+				return x.v, nil
+			}
+		}),
+		cancel: cancel,
+	}
+}
+
+// Collect returns all elements of it as a slice.
+//
+// This holds all elements in memory, so it must be finite and not too large.
+// If it yields error except ErrEOI, returns the elements yielded before the error and the error.
+func Collect(it Iterator) ([]interface{}, error) {
+	xs := []interface{}{}
+	for {
+		x, err := it.Next()
+		if err == ErrEOI {
+			return xs, nil
+		}
+		if err != nil {
+			return xs, err
+		}
+		xs = append(xs, x)
+	}
+}
+
+/* Iterator combinators */
+
+// ZipWith returns a new Iterator that yields the results of f applied to the elements of a and b pairwise.
+//
+// f is a func(A, B) (C, error) or func(A, B) C.
+// The iterator ends when either a or b ends.
+// If f returns error, the iterator ends here with the error.
+func ZipWith(a, b Iterator, f interface{}) (Iterator, error) {
+	g, err := NewBiMapper(f)
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(func() (interface{}, error) {
+		x, err := a.Next()
+		if err != nil {
+			return nil, err
+		}
+		y, err := b.Next()
+		if err != nil {
+			return nil, err
+		}
+		return g.Apply(x, y)
+	}), nil
+}
+
+// Concat returns a new Iterator that yields all elements of its sequentially.
+//
+// If an iterator yields error except ErrEOI, the iterator ends here with the error.
+func Concat(its ...Iterator) (Iterator, error) {
+	var i int
+	return newIterator(func() (interface{}, error) {
+		for i < len(its) {
+			x, err := its[i].Next()
+			if err == ErrEOI {
+				// next iterator
+				i++
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+		return nil, ErrEOI
+	}), nil
+}
+
+// RoundRobin returns a new Iterator that yields an element from each of its in turn.
+//
+// The exhausted iterators are skipped, the iterator ends when all of its are exhausted.
+// If an iterator yields error except ErrEOI, the iterator ends here with the error.
+func RoundRobin(its ...Iterator) (Iterator, error) {
+	var (
+		active = append([]Iterator{}, its...)
+		i      int
+	)
+	return newIterator(func() (interface{}, error) {
+		for len(active) > 0 {
+			if i >= len(active) {
+				i = 0
+			}
+			x, err := active[i].Next()
+			if err == ErrEOI {
+				// remove the exhausted iterator, the next one takes its place
+				active = append(active[:i], active[i+1:]...)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			i++
+			return x, nil
+		}
+		return nil, ErrEOI
+	}), nil
+}
+
+type (
+	// teeSource shares it between the copies.
+	teeSource struct {
+		mux    sync.Mutex
+		it     Iterator
+		queues [][]interface{}
+		err    error
+	}
+)
+
+// next returns the next element for the i-th copy.
+func (s *teeSource) next(i int) (interface{}, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if len(s.queues[i]) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		x, err := s.it.Next()
+		if err != nil {
+			s.err = err
+			return nil, err
+		}
+		for j := range s.queues {
+			s.queues[j] = append(s.queues[j], x)
+		}
+	}
+	x := s.queues[i][0]
+	s.queues[i][0] = nil
+	s.queues[i] = s.queues[i][1:]
+	return x, nil
+}
+
+// Tee returns n independent iterators that yield all elements of it.
+//
+// The elements not yet read by a copy are buffered,
+// so memory grows with the lag between the fastest and the slowest copy.
+// All copies yield the same error that terminated it.
+// The copies can be read from different goroutines.
+// If n is not positive, returns ErrCannotCreateIterator.
+func Tee(it Iterator, n int) ([]Iterator, error) {
+	if n <= 0 {
+		return nil, ErrCannotCreateIterator
+	}
+	src := &teeSource{
+		it:     it,
+		queues: make([][]interface{}, n),
+	}
+	its := make([]Iterator, n)
+	for i := range its {
+		i := i
+		its[i] = newIterator(func() (interface{}, error) {
+			return src.next(i)
+		})
+	}
+	return its, nil
+}
+
+type (
+	// replaySource caches the elements of it.
+	replaySource struct {
+		mux sync.Mutex
+		it  Iterator
+		xs  []interface{}
+		err error
+	}
+)
+
+// get returns the i-th element of it.
+func (s *replaySource) get(i int) (interface{}, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if i < len(s.xs) {
+		return s.xs[i], nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	x, err := s.it.Next()
+	if err != nil {
+		s.err = err
+		return nil, err
+	}
+	s.xs = append(s.xs, x)
+	return x, nil
+}
+
+// NewReplayIterator returns a factory of iterators that yield all elements of it.
+//
+// The elements are read from it and cached on the first pass,
+// every iterator from the factory yields the cached elements and the error that terminated it.
+// This holds all elements in memory, so it must be finite.
+// If it is nil, returns ErrCannotCreateIterator.
+func NewReplayIterator(it Iterator) (func() Iterator, error) {
+	if it == nil {
+		return nil, ErrCannotCreateIterator
+	}
+	src := &replaySource{
+		it: it,
+	}
+	return func() Iterator {
+		var i int
+		return newIterator(func() (interface{}, error) {
+			x, err := src.get(i)
+			if err != nil {
+				return nil, err
+			}
+			i++
+			return x, nil
+		})
+	}, nil
+}
+
+// Cycle returns a new iterator that yields all elements of it times times.
+//
+// If times is negative, repeats forever.
+// The elements are cached on the first pass like NewReplayIterator(),
+// this holds all elements in memory, so it must be finite.
+// If it terminates with an error except ErrEOI, the error is yielded only at the end of the final pass,
+// the other passes end there silently.
+// If it yields no elements, the iterator ends after the first pass even if times is negative.
+// If it is nil, returns ErrCannotCreateIterator.
+func Cycle(it Iterator, times int) (Iterator, error) {
+	if it == nil {
+		return nil, ErrCannotCreateIterator
+	}
+	var (
+		src = &replaySource{
+			it: it,
+		}
+		pass int
+		i    int
+	)
+	return newIterator(func() (interface{}, error) {
+		for times < 0 || pass < times {
+			x, err := src.get(i)
+			if err == nil {
+				i++
+				return x, nil
+			}
+			if i == 0 || pass == times-1 {
+				// empty source or the final pass
+				return nil, err
+			}
+			pass++
+			i = 0
+		}
+		return nil, ErrEOI
+	}), nil
+}
+
+type (
+	mergeHead struct {
+		x interface{}
+		i int
+	}
+
+	// mergeHeap is a heap of the heads of the iterators to merge.
+	mergeHeap struct {
+		hs  []*mergeHead
+		f   Comparator
+		err error
+	}
+)
+
+func (s *mergeHeap) Len() int           { return len(s.hs) }
+func (s *mergeHeap) Swap(i, j int)      { s.hs[i], s.hs[j] = s.hs[j], s.hs[i] }
+func (s *mergeHeap) Push(x interface{}) { s.hs = append(s.hs, x.(*mergeHead)) }
+func (s *mergeHeap) Pop() interface{} {
+	x := s.hs[len(s.hs)-1]
+	s.hs = s.hs[:len(s.hs)-1]
+	return x
+}
+
+// Less compares heads by f, the head of the former iterator is less if they are equal.
+// If f returns error, records the first error.
+func (s *mergeHeap) Less(i, j int) bool {
+	if s.err != nil {
+		return false
+	}
+	a, b := s.hs[i], s.hs[j]
+	v, err := s.f.Apply(a.x, b.x)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	if v {
+		return true
+	}
+	w, err := s.f.Apply(b.x, a.x)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	return !w && a.i < b.i
+}
+
+// Merge returns a new Iterator that merges sorted its into one sorted iterator.
+//
+// f is a func(A, A) (bool, error) or func(A, A) bool that returns true if the left argument is less than the right.
+// Each of its must be sorted by f.
+// This pulls elements lazily and holds only the heads of its.
+// Equal elements are yielded in the order of its.
+// If f returns error or an iterator yields error except ErrEOI, the iterator ends here with the error.
+func Merge(f interface{}, its ...Iterator) (Iterator, error) {
+	g, err := NewComparator(f)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		h = &mergeHeap{
+			f: g,
+		}
+		isInitialized bool
+		// yielded is the head yielded last time, it should be advanced
+		yielded *mergeHead
+	)
+	return newIterator(func() (interface{}, error) {
+		if !isInitialized {
+			isInitialized = true
+			for i, it := range its {
+				x, err := it.Next()
+				if err == ErrEOI {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+				heap.Push(h, &mergeHead{
+					x: x,
+					i: i,
+				})
+			}
+			if h.err != nil {
+				return nil, h.err
+			}
+		}
+		if yielded != nil {
+			x, err := its[yielded.i].Next()
+			switch {
+			case err == ErrEOI:
+				heap.Pop(h)
+			case err != nil:
+				return nil, err
+			default:
+				yielded.x = x
+				heap.Fix(h, 0)
+			}
+			if h.err != nil {
+				return nil, h.err
+			}
+		}
+		if h.Len() == 0 {
+			return nil, ErrEOI
+		}
+		yielded = h.hs[0]
+		return yielded.x, nil
+	}), nil
+}
 
 /* IteratorFunc constructors */
 
@@ -229,6 +1003,33 @@ func newChanIteratorFunc(v interface{}) (IteratorFunc, error) {
 	}, nil
 }
 
+func newChanIteratorFuncWithContext(ctx context.Context, v interface{}) (IteratorFunc, error) {
+	t := reflect.TypeOf(v)
+	if !(t.Kind() == reflect.Chan && t.ChanDir() != reflect.SendDir) {
+		return nil, ErrCannotCreateIterator
+	}
+	cases := []reflect.SelectCase{
+		{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(v),
+		},
+		{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(ctx.Done()),
+		},
+	}
+	return func() (interface{}, error) {
+		if ctx.Err() != nil {
+			return nil, ErrEOI
+		}
+		chosen, x, ok := reflect.Select(cases)
+		if chosen == 0 && ok {
+			return x.Interface(), nil
+		}
+		return nil, ErrEOI
+	}, nil
+}
+
 func newMapIteratorFunc(v interface{}) (IteratorFunc, error) {
 	t := reflect.TypeOf(v)
 	if t.Kind() != reflect.Map {
@@ -2,8 +2,14 @@ package circle_test
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -79,11 +85,42 @@ func TestIteratorChannel(t *testing.T) {
 		"normal":  testIteratorChannel,
 		"failure": testIteratorChannelFailure,
 		"context": testIteratorChannelWithContext,
+		"close":   testIteratorChannelClose,
 	} {
 		t.Run(name, tc)
 	}
 }
 
+func testIteratorChannelClose(t *testing.T) {
+	var i int
+	it, err := circle.NewIterator(func() (interface{}, error) {
+		// infinite iterator
+		i++
+		return i, nil
+	})
+	assert.Nil(t, err)
+	c := it.Channel()
+	for range c.C() {
+		// abandon the channel
+		break
+	}
+	c.Close()
+	c.Close()
+	done := make(chan struct{})
+	go func() {
+		// the channel closes when the producer exits
+		for range c.C() {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("producer did not exit")
+	}
+	assert.Nil(t, c.Err())
+}
+
 func testIteratorChannelWithContext(t *testing.T) {
 	var i int
 	it, err := circle.NewIterator(func() (interface{}, error) {
@@ -257,3 +294,976 @@ func testMapIterator(t *testing.T) {
 	assert.Equal(t, "", cmp.Diff(v, d))
 	assert.Nil(t, c.Err())
 }
+
+func ExampleZipWith() {
+	a, _ := circle.NewIterator([]string{"a", "b", "c"})
+	b, _ := circle.NewIterator([]int{1, 2})
+	it, _ := circle.ZipWith(a, b, func(x string, y int) string {
+		return fmt.Sprintf("%s%d", x, y)
+	})
+	c := it.Channel()
+	for v := range c.C() {
+		fmt.Println(v)
+	}
+	fmt.Println(c.Err())
+	// Output:
+	// a1
+	// b2
+	// <nil>
+}
+
+func TestZipWith(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.ZipWith(circle.MustNewIterator(nil), circle.MustNewIterator(nil), func(int) int { return 0 })
+		assert.Equal(t, circle.ErrInvalidMapper, err)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		a, err := circle.NewIterator([]int{1, 2, 3})
+		assert.Nil(t, err)
+		b, err := circle.NewIterator([]int{1, 0, 1})
+		assert.Nil(t, err)
+		it, err := circle.ZipWith(a, b, func(x, y int) (int, error) {
+			if y == 0 {
+				return 0, errors.New("zero division")
+			}
+			return x / y, nil
+		})
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, errors.New("zero division"), err)
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+}
+
+func TestConcat(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		it, err := circle.Concat()
+		assert.Nil(t, err)
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		it, err := circle.Concat(
+			circle.MustNewIterator([]int{1, 2}),
+			circle.MustNewIterator(nil),
+			circle.MustNewIterator([]int{3}),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		it, err := circle.Concat(
+			circle.MustNewIterator([]int{1}),
+			circle.MustNewIterator(func() (interface{}, error) { return nil, e }),
+			circle.MustNewIterator([]int{3}),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+	})
+}
+
+func TestRoundRobin(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		it, err := circle.RoundRobin()
+		assert.Nil(t, err)
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("interleave", func(t *testing.T) {
+		it, err := circle.RoundRobin(
+			circle.MustNewIterator([]int{1, 4, 6, 8}),
+			circle.MustNewIterator([]int{2}),
+			circle.MustNewIterator([]int{3, 5, 7}),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3, 4, 5, 6, 7, 8}, got))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		it, err := circle.RoundRobin(
+			circle.MustNewIterator([]int{1, 3}),
+			circle.MustNewIterator(func() circle.IteratorFunc {
+				var called bool
+				return func() (interface{}, error) {
+					if called {
+						return nil, e
+					}
+					called = true
+					return 2, nil
+				}
+			}()),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+	})
+}
+
+func ExampleNewLineIterator() {
+	it, _ := circle.NewLineIterator(strings.NewReader("one\ntwo\n\nthree"))
+	for {
+		v, err := it.Next()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%q\n", v)
+	}
+	// Output:
+	// "one"
+	// "two"
+	// ""
+	// "three"
+	// EOI
+}
+
+type errReader struct {
+	err error
+}
+
+func (s *errReader) Read([]byte) (int, error) { return 0, s.err }
+
+// lineReader yields endless lines.
+type lineReader struct {
+	mux   sync.Mutex
+	reads int
+}
+
+func (s *lineReader) Read(p []byte) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.reads++
+	return copy(p, "line\n"), nil
+}
+
+func (s *lineReader) count() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.reads
+}
+
+func TestLineIterator(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("read")
+		it, err := circle.NewLineIterator(io.MultiReader(strings.NewReader("one\n"), &errReader{err: e}))
+		assert.Nil(t, err)
+		c := it.Channel()
+		got := []interface{}{}
+		for v := range c.C() {
+			got = append(got, v)
+		}
+		assert.Equal(t, "", cmp.Diff([]interface{}{"one"}, got))
+		assert.Equal(t, e, c.Err())
+	})
+
+	t.Run("context", func(t *testing.T) {
+		r, w := io.Pipe()
+		defer r.Close()
+		go func() {
+			// infinite reader
+			for {
+				if _, err := fmt.Fprintln(w, "line"); err != nil {
+					return
+				}
+			}
+		}()
+		it, err := circle.NewLineIterator(r)
+		assert.Nil(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		c := it.ChannelWithContext(ctx)
+		var n int
+		for range c.C() {
+			n++
+			cancel()
+		}
+		assert.True(t, n >= 1)
+		assert.Nil(t, c.Err())
+	})
+}
+
+func TestLineIteratorWithContext(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		e := errors.New("read")
+		it, err := circle.NewLineIteratorWithContext(
+			context.Background(),
+			io.MultiReader(strings.NewReader("one\ntwo\n"), &errReader{err: e}),
+		)
+		assert.Nil(t, err)
+		got, err := circle.Collect(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{"one", "two"}, got))
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("eof", func(t *testing.T) {
+		it, err := circle.NewLineIteratorWithContext(context.Background(), strings.NewReader("one\n\nthree"))
+		assert.Nil(t, err)
+		got, err := circle.Collect(it)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{"one", "", "three"}, got))
+	})
+
+	t.Run("cancel blocked read", func(t *testing.T) {
+		r, w := io.Pipe()
+		defer r.Close()
+		go func() {
+			// write a line and block
+			fmt.Fprintln(w, "line")
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		it, err := circle.NewLineIteratorWithContext(ctx, r)
+		assert.Nil(t, err)
+		v, err := it.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, "line", v)
+
+		done := make(chan error)
+		go func() {
+			_, err := it.Next()
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		select {
+		case err := <-done:
+			assert.Equal(t, circle.ErrEOI, err)
+		case <-time.After(time.Second):
+			t.Fatal("blocked read was not interrupted")
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		r := &lineReader{}
+		it, err := circle.NewLineIteratorWithContext(context.Background(), r)
+		assert.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			v, err := it.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, "line", v)
+		}
+		// abandon the endless lines
+		it.Close()
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+
+		// the goroutine exits and stops reading r
+		time.Sleep(20 * time.Millisecond)
+		stopped := r.count()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, stopped, r.count())
+	})
+}
+
+func TestStringIterator(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		src   string
+		want  []interface{}
+	}{
+		{
+			title: "empty",
+			src:   "",
+			want:  []interface{}{},
+		},
+		{
+			title: "ascii",
+			src:   "abc",
+			want:  []interface{}{'a', 'b', 'c'},
+		},
+		{
+			title: "multibyte",
+			src:   "円環a",
+			want:  []interface{}{'円', '環', 'a'},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			it, err := circle.NewStringIterator(tc.src)
+			assert.Nil(t, err)
+			c := it.Channel()
+			got := []interface{}{}
+			for v := range c.C() {
+				got = append(got, v)
+			}
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+			assert.Nil(t, c.Err())
+		})
+	}
+}
+
+func TestRangeIterator(t *testing.T) {
+	t.Run("zero step", func(t *testing.T) {
+		_, err := circle.NewRangeIterator(0, 1, 0)
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	for _, tc := range []struct {
+		title             string
+		start, stop, step int
+		want              []int
+	}{
+		{
+			title: "empty",
+			start: 1,
+			stop:  1,
+			step:  1,
+			want:  []int{},
+		},
+		{
+			title: "increase",
+			start: 0,
+			stop:  5,
+			step:  2,
+			want:  []int{0, 2, 4},
+		},
+		{
+			title: "decrease",
+			start: 3,
+			stop:  -1,
+			step:  -1,
+			want:  []int{3, 2, 1, 0},
+		},
+		{
+			title: "wrong direction",
+			start: 0,
+			stop:  5,
+			step:  -1,
+			want:  []int{},
+		},
+		{
+			title: "near max int",
+			start: math.MaxInt - 1,
+			stop:  math.MaxInt,
+			step:  2,
+			want:  []int{math.MaxInt - 1},
+		},
+		{
+			title: "to max int",
+			start: math.MaxInt - 4,
+			stop:  math.MaxInt,
+			step:  3,
+			want:  []int{math.MaxInt - 4, math.MaxInt - 1},
+		},
+		{
+			title: "near min int",
+			start: math.MinInt + 1,
+			stop:  math.MinInt,
+			step:  -2,
+			want:  []int{math.MinInt + 1},
+		},
+		{
+			title: "huge step",
+			start: 0,
+			stop:  math.MaxInt,
+			step:  math.MaxInt,
+			want:  []int{0},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			it, err := circle.NewRangeIterator(tc.start, tc.stop, tc.step)
+			assert.Nil(t, err)
+			got, err := iteratorToInts(it)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+}
+
+func TestCollect(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		got, err := circle.Collect(circle.MustNewIterator(nil))
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{}, got))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		it, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		got, err := circle.Collect(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2}, got))
+	})
+}
+
+func TestNewIteratorWithContext(t *testing.T) {
+	t.Run("not channel", func(t *testing.T) {
+		it, err := circle.NewIteratorWithContext(context.Background(), []int{1, 2})
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+	})
+
+	t.Run("send only channel", func(t *testing.T) {
+		_, err := circle.NewIteratorWithContext(context.Background(), make(chan<- int))
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	t.Run("channel", func(t *testing.T) {
+		c := make(chan int, 2)
+		c <- 1
+		c <- 2
+		close(c)
+		it, err := circle.NewIteratorWithContext(context.Background(), c)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+	})
+
+	t.Run("cancel blocked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := make(chan int, 1)
+		c <- 1
+		it, err := circle.NewIteratorWithContext(ctx, c)
+		assert.Nil(t, err)
+		v, err := it.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		// c is never closed
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+}
+
+type (
+	// fakeRowsDB is a database/sql driver that yields rows of a single column.
+	fakeRowsDB struct {
+		values []int
+		err    error
+		closed bool
+	}
+	fakeRowsConn struct {
+		db *fakeRowsDB
+	}
+	fakeRowsStmt struct {
+		db *fakeRowsDB
+	}
+	fakeRows struct {
+		db *fakeRowsDB
+		i  int
+	}
+)
+
+func (s *fakeRowsDB) Connect(context.Context) (driver.Conn, error) { return &fakeRowsConn{db: s}, nil }
+func (s *fakeRowsDB) Driver() driver.Driver                        { return nil }
+
+func (s *fakeRowsConn) Prepare(string) (driver.Stmt, error) { return &fakeRowsStmt{db: s.db}, nil }
+func (*fakeRowsConn) Close() error                          { return nil }
+func (*fakeRowsConn) Begin() (driver.Tx, error)             { return nil, errors.New("not supported") }
+
+func (*fakeRowsStmt) Close() error  { return nil }
+func (*fakeRowsStmt) NumInput() int { return -1 }
+func (*fakeRowsStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeRowsStmt) Query([]driver.Value) (driver.Rows, error) { return &fakeRows{db: s.db}, nil }
+
+func (*fakeRows) Columns() []string { return []string{"v"} }
+func (s *fakeRows) Close() error {
+	s.db.closed = true
+	return nil
+}
+func (s *fakeRows) Next(dest []driver.Value) error {
+	if s.i >= len(s.db.values) {
+		if s.db.err != nil {
+			return s.db.err
+		}
+		return io.EOF
+	}
+	dest[0] = int64(s.db.values[s.i])
+	s.i++
+	return nil
+}
+
+func TestRowsIterator(t *testing.T) {
+	query := func(t *testing.T, db *fakeRowsDB) *sql.Rows {
+		rows, err := sql.OpenDB(db).Query("select v")
+		assert.Nil(t, err)
+		return rows
+	}
+	scan := func(rows *sql.Rows) (interface{}, error) {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		if v < 0 {
+			return nil, errors.New("negative")
+		}
+		return v, nil
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.NewRowsIterator(nil, scan)
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		db := &fakeRowsDB{
+			values: []int{1, 2, 3},
+		}
+		it, err := circle.NewRowsIterator(query(t, db), scan)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+		assert.True(t, db.closed)
+	})
+
+	t.Run("rows error", func(t *testing.T) {
+		e := errors.New("error")
+		db := &fakeRowsDB{
+			values: []int{1},
+			err:    e,
+		}
+		it, err := circle.NewRowsIterator(query(t, db), scan)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+		assert.True(t, db.closed)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		db := &fakeRowsDB{
+			values: []int{1, -1, 2},
+		}
+		it, err := circle.NewRowsIterator(query(t, db), scan)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, errors.New("negative"), err)
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+		assert.True(t, db.closed)
+	})
+}
+
+func TestIteratorDrain(t *testing.T) {
+	t.Run("consumed", func(t *testing.T) {
+		var n int
+		it := circle.MustNewIterator(func() (interface{}, error) {
+			if n >= 3 {
+				return nil, circle.ErrEOI
+			}
+			n++
+			return n, nil
+		})
+		assert.Nil(t, it.Drain())
+		assert.Equal(t, 3, n)
+		assert.Nil(t, it.Drain())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var calls int
+		e := errors.New("error")
+		it := circle.MustNewIterator(func() (interface{}, error) {
+			calls++
+			if calls > 2 {
+				return nil, e
+			}
+			return calls, nil
+		})
+		assert.Equal(t, e, it.Drain())
+		// the iterator has already ended
+		assert.Nil(t, it.Drain())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		it, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, -1})).
+			Filter(func(x int) (bool, error) {
+				if x < 0 {
+					return false, errors.New("negative")
+				}
+				return true, nil
+			}).
+			Execute()
+		assert.Nil(t, err)
+		assert.Equal(t, "0 negative", it.Drain().Error())
+	})
+}
+
+func TestIteratorCount(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		n, err := circle.MustNewIterator(nil).Count()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("counted", func(t *testing.T) {
+		it := circle.MustNewIterator([]int{1, 2, 3})
+		n, err := it.Count()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, n)
+		// the iterator has already ended
+		n, err = it.Count()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		it, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		n, err := it.Count()
+		assert.Equal(t, e, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		it, err := circle.NewStreamBuilder(circle.MustNewIterator([]int{1, 2, 3, 4})).
+			Filter(func(x int) bool { return x%2 == 0 }).
+			Execute()
+		assert.Nil(t, err)
+		n, err := it.Count()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+	})
+}
+
+func TestBufferedIterator(t *testing.T) {
+	t.Run("slow producer", func(t *testing.T) {
+		var i int
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			if i >= 5 {
+				return nil, circle.ErrEOI
+			}
+			time.Sleep(10 * time.Millisecond)
+			i++
+			return i, nil
+		})
+		got, err := circle.Collect(circle.NewBufferedIterator(src, 2))
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2, 3, 4, 5}, got))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		it := circle.NewBufferedIterator(src, 10)
+		got, err := circle.Collect(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2}, got))
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			return 1, nil
+		})
+		it := circle.NewBufferedIteratorWithContext(ctx, src, 1)
+		v, err := it.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, v)
+		cancel()
+		_, err = it.Next()
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		var (
+			mux   sync.Mutex
+			calls int
+		)
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			mux.Lock()
+			defer mux.Unlock()
+			calls++
+			return calls, nil
+		})
+		it := circle.NewBufferedIterator(src, 2)
+		for i := 1; i <= 3; i++ {
+			v, err := it.Next()
+			assert.Nil(t, err)
+			assert.Equal(t, i, v)
+		}
+		// abandon the infinite iterator
+		it.Close()
+		_, err := it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+
+		// the goroutine exits and stops reading the source
+		time.Sleep(20 * time.Millisecond)
+		mux.Lock()
+		stopped := calls
+		mux.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, stopped, calls)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	less := func(x, y int) bool { return x < y }
+
+	t.Run("invalid comparator", func(t *testing.T) {
+		_, err := circle.Merge(func(x int) bool { return true })
+		assert.Equal(t, circle.ErrInvalidComparator, err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		it, err := circle.Merge(less)
+		assert.Nil(t, err)
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		it, err := circle.Merge(less,
+			circle.MustNewIterator([]int{1, 4, 7}),
+			circle.MustNewIterator(nil),
+			circle.MustNewIterator([]int{2, 5, 8, 9}),
+			circle.MustNewIterator([]int{3, 6}),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, got))
+	})
+
+	t.Run("equal elements", func(t *testing.T) {
+		it, err := circle.Merge(func(x, y circle.Tuple) bool {
+			a, _ := x.GetInt(0)
+			b, _ := y.GetInt(0)
+			return a < b
+		},
+			circle.MustNewIterator([]circle.Tuple{circle.NewTuple(1, "a"), circle.NewTuple(2, "a")}),
+			circle.MustNewIterator([]circle.Tuple{circle.NewTuple(1, "b"), circle.NewTuple(2, "b")}),
+		)
+		assert.Nil(t, err)
+		got := []string{}
+		for v := range it.Channel().C() {
+			s, _ := v.(circle.Tuple).GetString(1)
+			got = append(got, s)
+		}
+		assert.Equal(t, "", cmp.Diff([]string{"a", "b", "a", "b"}, got))
+	})
+
+	t.Run("comparator error", func(t *testing.T) {
+		e := errors.New("cannot compare")
+		it, err := circle.Merge(func(x, y int) (bool, error) {
+			if x == 3 || y == 3 {
+				return false, e
+			}
+			return x < y, nil
+		},
+			circle.MustNewIterator([]int{1, 3}),
+			circle.MustNewIterator([]int{2, 4}),
+		)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+	})
+}
+
+func TestTee(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.Tee(circle.MustNewIterator(nil), 0)
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		its, err := circle.Tee(circle.MustNewIterator([]int{1, 2, 3}), 3)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(its))
+		{
+			v, err := its[0].Next()
+			assert.Nil(t, err)
+			assert.Equal(t, 1, v)
+		}
+		for _, it := range []circle.Iterator{its[1], its[0], its[2]} {
+			got, err := iteratorToInts(it)
+			assert.Equal(t, circle.ErrEOI, err)
+			if it == its[0] {
+				assert.Equal(t, "", cmp.Diff([]int{2, 3}, got))
+				continue
+			}
+			assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		src, err := circle.Concat(circle.MustNewIterator([]int{1}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		its, err := circle.Tee(src, 2)
+		assert.Nil(t, err)
+		for _, it := range its {
+			got, err := iteratorToInts(it)
+			assert.Equal(t, e, err)
+			assert.Equal(t, "", cmp.Diff([]int{1}, got))
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		src := make([]int, 1000)
+		for i := range src {
+			src[i] = i
+		}
+		its, err := circle.Tee(circle.MustNewIterator(src), 4)
+		assert.Nil(t, err)
+		var wg sync.WaitGroup
+		results := make([][]int, len(its))
+		for i, it := range its {
+			i, it := i, it
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], _ = iteratorToInts(it)
+			}()
+		}
+		wg.Wait()
+		for _, got := range results {
+			assert.Equal(t, "", cmp.Diff(src, got))
+		}
+	})
+}
+
+func TestReplayIterator(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.NewReplayIterator(nil)
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	t.Run("do", func(t *testing.T) {
+		var calls int
+		src := circle.MustNewIterator(func() (interface{}, error) {
+			if calls >= 3 {
+				return nil, circle.ErrEOI
+			}
+			calls++
+			return calls, nil
+		})
+		f, err := circle.NewReplayIterator(src)
+		assert.Nil(t, err)
+		for i := 0; i < 2; i++ {
+			got, err := iteratorToInts(f())
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff([]int{1, 2, 3}, got))
+		}
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		e := errors.New("error")
+		src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		f, err := circle.NewReplayIterator(src)
+		assert.Nil(t, err)
+		for i := 0; i < 2; i++ {
+			got, err := iteratorToInts(f())
+			assert.Equal(t, e, err)
+			assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+		}
+	})
+}
+
+func TestCycle(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := circle.Cycle(nil, 1)
+		assert.Equal(t, circle.ErrCannotCreateIterator, err)
+	})
+
+	for _, tc := range []struct {
+		title string
+		src   []int
+		times int
+		want  []int
+	}{
+		{
+			title: "zero",
+			src:   []int{1, 2},
+			times: 0,
+			want:  []int{},
+		},
+		{
+			title: "once",
+			src:   []int{1, 2},
+			times: 1,
+			want:  []int{1, 2},
+		},
+		{
+			title: "three times",
+			src:   []int{1, 2},
+			times: 3,
+			want:  []int{1, 2, 1, 2, 1, 2},
+		},
+		{
+			title: "empty forever",
+			times: -1,
+			want:  []int{},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			it, err := circle.Cycle(circle.MustNewIterator(tc.src), tc.times)
+			assert.Nil(t, err)
+			got, err := iteratorToInts(it)
+			assert.Equal(t, circle.ErrEOI, err)
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+		})
+	}
+
+	t.Run("forever", func(t *testing.T) {
+		it, err := circle.Cycle(circle.MustNewIterator([]int{1, 2, 3}), -1)
+		assert.Nil(t, err)
+		got := []int{}
+		for i := 0; i < 7; i++ {
+			x, err := it.Next()
+			assert.Nil(t, err)
+			got = append(got, x.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 3, 1, 2, 3, 1}, got))
+	})
+
+	t.Run("error on the final pass", func(t *testing.T) {
+		e := errors.New("error")
+		src, err := circle.Concat(circle.MustNewIterator([]int{1, 2}), circle.MustNewIterator(func() (interface{}, error) {
+			return nil, e
+		}))
+		assert.Nil(t, err)
+		it, err := circle.Cycle(src, 2)
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, e, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2, 1, 2}, got))
+	})
+}
@@ -15,9 +15,25 @@ type (
 		Err() error
 	}
 
+	// NodeObserver observes the iteration of StreamNode.
+	//
+	// The callbacks are called synchronously by the iteration,
+	// so the elapsed time between the callbacks is the time that the node spent to yield the element.
+	NodeObserver interface {
+		// OnEmit is called when the node yields v.
+		OnEmit(nodeID string, v interface{})
+		// OnError is called when the node yields err, not called on ErrEOI.
+		OnError(nodeID string, err error)
+	}
+
+	// StreamNodeOption is an option of StreamNode.
+	StreamNodeOption func(*streamNode)
+
 	streamNode struct {
 		executor Executor
 		nid      string
+		obs      NodeObserver
+		format   func(nodeID string, err error) error
 	}
 	errStreamNode struct {
 		nid string
@@ -26,10 +42,51 @@ type (
 )
 
 // NewStreamNode returns a new StreamNode.
-func NewStreamNode(executor Executor, nid string) StreamNode {
-	return &streamNode{
+func NewStreamNode(executor Executor, nid string, opt ...StreamNodeOption) StreamNode {
+	s := &streamNode{
 		executor: executor,
 		nid:      nid,
+		format:   formatNodeError,
+	}
+	for _, o := range opt {
+		o(s)
+	}
+	return s
+}
+
+// WithStreamNodeObserver sets an observer of StreamNode.
+func WithStreamNodeObserver(obs NodeObserver) StreamNodeOption {
+	return func(s *streamNode) {
+		s.obs = obs
+	}
+}
+
+// WithStreamNodeErrorFormatter sets a function that converts the errors yielded from StreamNode.
+// f receives the node id and the original error.
+// By default, the error is NodeError.
+func WithStreamNodeErrorFormatter(f func(nodeID string, err error) error) StreamNodeOption {
+	return func(s *streamNode) {
+		s.format = f
+	}
+}
+
+type (
+	// NodeError is an error from StreamNode.
+	NodeError struct {
+		// NodeID is the id of the failed node.
+		NodeID string
+		// Err is the original error.
+		Err error
+	}
+)
+
+func (s NodeError) Error() string { return fmt.Sprintf("%s %v", s.NodeID, s.Err) }
+func (s NodeError) Unwrap() error { return s.Err }
+
+func formatNodeError(nodeID string, err error) error {
+	return NodeError{
+		NodeID: nodeID,
+		Err:    err,
 	}
 }
 
@@ -47,8 +104,10 @@ func (s *streamNode) Execute() (Iterator, error) {
 		return nil, err
 	}
 	return &StreamNodeIterator{
-		it:  it,
-		nid: s.nid,
+		it:     it,
+		nid:    s.nid,
+		obs:    s.obs,
+		format: s.format,
 	}, nil
 }
 func (s *streamNode) ID() string { return s.nid }
@@ -61,8 +120,10 @@ func (s *errStreamNode) Err() error               { return s.err }
 type (
 	// StreamNodeIterator is an Iterator that appends node id to iterator errors.
 	StreamNodeIterator struct {
-		it  Iterator
-		nid string
+		it     Iterator
+		nid    string
+		obs    NodeObserver
+		format func(nodeID string, err error) error
 	}
 )
 
@@ -73,10 +134,18 @@ func (s *StreamNodeIterator) Next() (interface{}, error) {
 		return nil, ErrEOI
 	}
 	if err != nil {
-		return nil, fmt.Errorf("%s %w", s.nid, err)
+		if s.obs != nil {
+			s.obs.OnError(s.nid, err)
+		}
+		return nil, s.format(s.nid, err)
+	}
+	if s.obs != nil {
+		s.obs.OnEmit(s.nid, r)
 	}
 	return r, nil
 }
+func (s *StreamNodeIterator) Drain() error        { return drain(s) }
+func (s *StreamNodeIterator) Count() (int, error) { return count(s) }
 func (s *StreamNodeIterator) channel(ctx context.Context) IteratorChannel {
 	it, _ := NewIterator(s.Next)
 	return it.ChannelWithContext(ctx)
@@ -1,8 +1,11 @@
 package circle
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 )
 
 type (
@@ -11,24 +14,137 @@ type (
 		// Map maps Stream.
 		// Convert each element by f.
 		// If f returns error, the element is filtered from this stream.
+		// See WithCollectErrors().
 		Map(f Mapper, opt ...StreamOption) Stream
 		// Filter filters Stream.
 		// Select elements by f.
 		// If f returns error, stops streaming.
+		// See WithCollectErrors().
 		Filter(f Filter, opt ...StreamOption) Stream
+		// TakeWhile yields elements while f returns true.
+		// If f returns error, stops streaming.
+		TakeWhile(f Filter, opt ...StreamOption) Stream
+		// DropWhile discards elements while f returns true, then yields the rest.
+		// If f returns error, stops streaming.
+		DropWhile(f Filter, opt ...StreamOption) Stream
+		// Distinct removes duplicated elements from Stream.
+		// If an element is not hashable, stops streaming.
+		Distinct(opt ...StreamOption) Stream
+		// DistinctBy removes elements that have duplicated keys extracted by f from Stream.
+		// If f returns error or a key is not hashable, stops streaming.
+		DistinctBy(f Mapper, opt ...StreamOption) Stream
+		// DistinctWindow removes elements that equal one of the last n distinct elements from Stream.
+		// See NewDistinctWindowExecutor().
+		DistinctWindow(n int, opt ...StreamOption) Stream
+		// Dedup removes consecutive duplicated elements from Stream.
+		// If an element is not comparable, stops streaming.
+		Dedup(opt ...StreamOption) Stream
+		// DedupBy removes elements that have the same key extracted by f as the previous element from Stream.
+		// If f returns error or a key is not comparable, stops streaming.
+		DedupBy(f Mapper, opt ...StreamOption) Stream
+		// Intersperse yields sep between successive elements of Stream.
+		Intersperse(sep interface{}, opt ...StreamOption) Stream
+		// Peek calls f with each element and yields it unchanged.
+		// If f returns error, stops streaming.
+		Peek(f Consumer, opt ...StreamOption) Stream
+		// MapError converts the error that stops the upstream by f.
+		// See NewMapErrorExecutor().
+		MapError(f func(error) error, opt ...StreamOption) Stream
+		// Recover converts the error that stops the upstream into the last element by f.
+		// See NewRecoverExecutor().
+		Recover(f Mapper, opt ...StreamOption) Stream
 		// Aggregate aggregates Stream.
 		// Aggregate elements by f and iv as initial value.
 		Aggregate(f Aggregator, iv interface{}, opt ...StreamOption) Stream
+		// AggregateWhile aggregates Stream like Aggregate with foldl while cond returns true.
+		// See NewAggregateWhileExecutor().
+		AggregateWhile(f Aggregator, iv interface{}, cond Filter, opt ...StreamOption) Stream
+		// GroupBy groups elements of Stream by keys extracted by f.
+		// Yield Tuple(key, []value) in the order of the first occurrence of keys.
+		// If f returns error, the element is filtered from this stream.
+		GroupBy(f Mapper, opt ...StreamOption) Stream
+		// Scan aggregates Stream like Aggregate with foldl and yields every intermediate value.
+		Scan(f Aggregator, iv interface{}, opt ...StreamOption) Stream
 		// Sort sorts Stream.
 		// Sort elements by f.
-		// If f returns error, the element is regarded as bigger.
+		// The sort is stable, equal elements keep their original order.
+		// If f returns error, the element is regarded as bigger by default,
+		// see WithStrictSort().
+		// This buffers all elements, see WithMaxBuffer().
 		Sort(f Comparator, opt ...StreamOption) Stream
+		// Concat appends others to Stream.
+		Concat(others []Iterator, opt ...StreamOption) Stream
+		// Enumerate pairs each element of Stream with its index as Tuple(index, element).
+		// The index starts from 0, see WithEnumerateStart().
+		Enumerate(opt ...StreamOption) Stream
+		// Reverse reverses Stream.
+		// This buffers all elements, so Stream must be finite, see WithMaxBuffer().
+		Reverse(opt ...StreamOption) Stream
 		// Flat flattens Stream.
-		// See NewFlatExecutor().
+		// See NewFlatExecutor() and WithFlatString().
 		Flat(opt ...StreamOption) Stream
+		// FlatChannel flattens Stream of receivable channels.
+		// See NewFlatChannelExecutor().
+		FlatChannel(opt ...StreamOption) Stream
+		// FlatDeep flattens nested slices and arrays of Stream up to depth levels.
+		// See NewFlatDeepExecutor() and WithFlatTuple().
+		FlatDeep(depth int, opt ...StreamOption) Stream
+		// Reduce aggregates Stream by f and iv as initial value,
+		// and returns the aggregated value.
+		Reduce(f Aggregator, iv interface{}, opt ...StreamOption) (interface{}, error)
+		// Partition splits Stream into the iterator of the elements that f returns true
+		// and the iterator of the elements that f returns false.
+		// If f returns error, both iterators end with the error.
+		Partition(f Filter) (Iterator, Iterator, error)
+		// Chunk groups consecutive elements of Stream into []interface{} of length size.
+		// The last chunk may be shorter than size.
+		Chunk(size int, opt ...StreamOption) Stream
+		// WindowAggregate groups consecutive elements of Stream into windows of length size
+		// and yields the aggregated value of each window by f and iv as initial value.
+		// The last window may be shorter than size.
+		WindowAggregate(size int, f Aggregator, iv interface{}, opt ...StreamOption) Stream
+		// StepBy yields every n-th element of Stream.
+		StepBy(n int, opt ...StreamOption) Stream
+		// Window yields overlapping Tuples of size consecutive elements of Stream, advancing by one element.
+		Window(size int, opt ...StreamOption) Stream
+		// TopN selects the n largest elements of Stream by f, a "less" function,
+		// and yields them from the largest to the smallest.
+		// See NewTopNExecutor().
+		TopN(n int, f Comparator, opt ...StreamOption) Stream
+		// BottomN selects the n smallest elements of Stream by f, a "less" function,
+		// and yields them from the smallest to the largest.
+		// See NewBottomNExecutor().
+		BottomN(n int, f Comparator, opt ...StreamOption) Stream
+		// FlatMap maps and flattens Stream.
+		// See NewFlatMapExecutor().
+		FlatMap(f Mapper, opt ...StreamOption) Stream
 		// Consume consumes Stream.
 		// If f returns error, stops consuming.
 		Consume(f Consumer, opt ...StreamOption) error
+		// ConsumeWithContext consumes Stream like Consume.
+		// If ctx is canceled, stops consuming and returns ctx.Err().
+		ConsumeWithContext(ctx context.Context, f Consumer, opt ...StreamOption) error
+		// ConsumeBatch consumes Stream by f with []interface{} of up to size consecutive elements.
+		// See NewBatchConsumeExecutor().
+		ConsumeBatch(f Consumer, size int, opt ...StreamOption) error
+		// ConsumeParallel consumes Stream by f on workers goroutines.
+		// The order of consumption is not guaranteed.
+		// The first error stops consuming and is returned.
+		// See NewParallelConsumeExecutor().
+		ConsumeParallel(f Consumer, workers int, opt ...StreamOption) error
+		// ExecuteWithContext executes Stream like Execute.
+		// Each stage stops reading its source and the resulting iterator yields ctx.Err()
+		// after ctx is canceled.
+		// The resulting iterator's Channel() also honors ctx.
+		ExecuteWithContext(ctx context.Context) (Iterator, error)
+		// Tap returns an iterator that yields the elements flowing out of the node of nodeID,
+		// the main stream is not disturbed.
+		// The iterator yields ErrEOI until Stream is executed, and follows the latest execution.
+		// The elements are buffered until the tap reads them like Tee(),
+		// so read the tap or the memory grows.
+		// If there are multiple nodes of nodeID, taps the first one.
+		// If no such node, returns ErrNodeNotFound.
+		Tap(nodeID string) (Iterator, error)
 		Executor
 	}
 
@@ -36,29 +152,53 @@ type (
 	ExecutorFactory   func(Iterator) (Executor, error)
 
 	stream struct {
-		it    Iterator
-		nodes []StreamNodeFactory
+		it            Iterator
+		nodes         []StreamNodeFactory
+		collectErrors bool
+		errs          *errorList
+		onComplete    []func(error)
+		// nodeIDs are the ids of nodes.
+		nodeIDs []string
+		// taps are the taps of nodes by the index of the node.
+		taps map[int][]*tapIterator
+	}
+
+	// tapIterator is a view of the node, the source is replaced on each execution.
+	tapIterator struct {
+		mux sync.Mutex
+		it  Iterator
 	}
 )
 
 var (
 	ErrCannotCreateStream = errors.New("cannot create stream")
+	// ErrEmptyStream is returned by terminals that require at least one element.
+	ErrEmptyStream = errors.New("empty stream")
+	// ErrNodeNotFound is returned by Tap calls when the node does not exist.
+	ErrNodeNotFound = errors.New("node not found")
 )
 
 // NewStream returns a new Stream.
 func NewStream(it Iterator) Stream {
 	return &stream{
-		it:    it,
-		nodes: []StreamNodeFactory{},
+		it:      it,
+		nodes:   []StreamNodeFactory{},
+		nodeIDs: []string{},
+		taps:    map[int][]*tapIterator{},
 	}
 }
 
-func (s *stream) Execute() (Iterator, error) { return s.connect() }
+func (s *stream) Execute() (Iterator, error) { return s.connect(context.Background()) }
+func (s *stream) ExecuteWithContext(ctx context.Context) (Iterator, error) {
+	return s.connect(ctx)
+}
 
-func (s *stream) connect() (Iterator, error) {
+func (s *stream) connect(ctx context.Context) (Iterator, error) {
+	// reset the errors for each execution
+	s.errs = &errorList{}
 	var it Iterator = s.it
-	for _, f := range s.nodes {
-		n := f(it)
+	for i, f := range s.nodes {
+		n := f(withContextIterator(ctx, it))
 		if err := n.Err(); err != nil {
 			return nil, fmt.Errorf("%w %s %v", ErrCannotCreateStream, n.ID(), err)
 		}
@@ -66,36 +206,226 @@ func (s *stream) connect() (Iterator, error) {
 		if err != nil {
 			return nil, fmt.Errorf("%w %s %v", ErrCannotCreateStream, n.ID(), err)
 		}
+		if taps := s.taps[i]; len(taps) > 0 {
+			its, err := Tee(nit, len(taps)+1)
+			if err != nil {
+				return nil, fmt.Errorf("%w %s %v", ErrCannotCreateStream, n.ID(), err)
+			}
+			nit = its[0]
+			for j, t := range taps {
+				t.set(its[j+1])
+			}
+		}
 		it = nit
 	}
+	it = withContextIterator(ctx, it)
+	if len(s.onComplete) > 0 {
+		it = s.withOnComplete(ctx, it)
+	}
+	if s.collectErrors {
+		return &errorCollectorIterator{
+			Iterator: it,
+			errs:     s.errs,
+		}, nil
+	}
 	return it, nil
 }
 
-func (s *stream) append(f ExecutorFactory, nodeID string) Stream {
+func (s *stream) Tap(nodeID string) (Iterator, error) {
+	for i, id := range s.nodeIDs {
+		if id == nodeID {
+			t := &tapIterator{}
+			s.taps[i] = append(s.taps[i], t)
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %s", ErrNodeNotFound, nodeID)
+}
+
+func (s *tapIterator) set(it Iterator) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.it = it
+}
+
+func (s *tapIterator) Next() (interface{}, error) {
+	s.mux.Lock()
+	it := s.it
+	s.mux.Unlock()
+	if it == nil {
+		// not executed yet
+		return nil, ErrEOI
+	}
+	return it.Next()
+}
+func (s *tapIterator) Drain() error             { return drain(s) }
+func (s *tapIterator) Count() (int, error)      { return count(s) }
+func (s *tapIterator) Channel() IteratorChannel { return newIteratorChannel(context.Background(), s) }
+func (s *tapIterator) ChannelWithContext(ctx context.Context) IteratorChannel {
+	return newIteratorChannel(ctx, s)
+}
+
+// withOnComplete returns a new iterator that calls the callbacks of WithOnComplete() once when it ends.
+func (s *stream) withOnComplete(ctx context.Context, it Iterator) Iterator {
+	oit := newIterator(func() (interface{}, error) {
+		x, err := it.Next()
+		if err != nil {
+			r := err
+			if r == ErrEOI {
+				r = nil
+			}
+			for _, f := range s.onComplete {
+				f(r)
+			}
+		}
+		return x, err
+	})
+	if ctx.Done() == nil {
+		return oit
+	}
+	// it already honors ctx, but Channel() should too
+	return &contextIterator{
+		Iterator: oit,
+		ctx:      ctx,
+	}
+}
+
+func (s *stream) nodeID(nodeID string) string {
 	if nodeID == "" {
-		nodeID = fmt.Sprint(len(s.nodes))
+		return fmt.Sprint(len(s.nodes))
+	}
+	return nodeID
+}
+
+// executorOptions returns ExecutorOptions for the next node from c.
+func (s *stream) executorOptions(c *StreamConfig) []ExecutorOption {
+	opts := []ExecutorOption{}
+	if c.CollectErrors {
+		s.collectErrors = true
+		nid := s.nodeID(c.NodeID)
+		format := formatNodeError
+		if c.ErrorFormatter != nil {
+			format = c.ErrorFormatter
+		}
+		opts = append(opts, WithExecutorErrorCollector(func(err error) {
+			// s.errs is renewed by connect
+			s.errs.add(format(nid, err))
+		}))
+	}
+	return opts
+}
+
+func (s *stream) append(f ExecutorFactory, c *StreamConfig) Stream {
+	nodeID := s.nodeID(c.NodeID)
+	s.nodeIDs = append(s.nodeIDs, nodeID)
+	if c.OnComplete != nil {
+		s.onComplete = append(s.onComplete, c.OnComplete)
 	}
 	s.nodes = append(s.nodes, func(it Iterator) StreamNode {
 		ex, err := f(it)
 		if err != nil {
 			return NewErrStreamNode(err, nodeID)
 		}
-		return NewStreamNode(ex, nodeID)
+		nopts := []StreamNodeOption{}
+		if c.Observer != nil {
+			nopts = append(nopts, WithStreamNodeObserver(c.Observer))
+		}
+		if c.ErrorFormatter != nil {
+			nopts = append(nopts, WithStreamNodeErrorFormatter(c.ErrorFormatter))
+		}
+		return NewStreamNode(ex, nodeID, nopts...)
 	})
 	return s
 }
 
 func (s *stream) Map(f Mapper, opt ...StreamOption) Stream {
 	c := newStreamConfig(opt...)
+	eopts := s.executorOptions(c)
+	if c.ApplyTimeout > 0 {
+		f = NewTimeoutMapper(f, c.ApplyTimeout)
+	}
 	return s.append(func(it Iterator) (Executor, error) {
-		return NewMapExecutor(f, it), nil
-	}, c.NodeID)
+		return NewMapExecutor(f, it, eopts...), nil
+	}, c)
 }
 func (s *stream) Filter(f Filter, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	eopts := s.executorOptions(c)
+	if c.ApplyTimeout > 0 {
+		f = NewTimeoutFilter(f, c.ApplyTimeout)
+	}
+	if c.ResumeOnError {
+		eopts = append(eopts, WithExecutorResumeOnError(true))
+	}
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewFilterExecutor(f, it, eopts...), nil
+	}, c)
+}
+func (s *stream) TakeWhile(f Filter, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewTakeWhileExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) DropWhile(f Filter, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDropWhileExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) Distinct(opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDistinctExecutor(it), nil
+	}, c)
+}
+func (s *stream) DistinctWindow(n int, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDistinctWindowExecutor(n, it)
+	}, c)
+}
+func (s *stream) DistinctBy(f Mapper, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDistinctByExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) Dedup(opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDedupExecutor(it), nil
+	}, c)
+}
+func (s *stream) DedupBy(f Mapper, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewDedupByExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) Intersperse(sep interface{}, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewIntersperseExecutor(sep, it), nil
+	}, c)
+}
+func (s *stream) Peek(f Consumer, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewPeekExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) MapError(f func(error) error, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewMapErrorExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) Recover(f Mapper, opt ...StreamOption) Stream {
 	c := newStreamConfig(opt...)
 	return s.append(func(it Iterator) (Executor, error) {
-		return NewFilterExecutor(f, it), nil
-	}, c.NodeID)
+		return NewRecoverExecutor(f, it), nil
+	}, c)
 }
 func (s *stream) Aggregate(f Aggregator, iv interface{}, opt ...StreamOption) Stream {
 	c := newStreamConfig(opt...)
@@ -105,29 +435,183 @@ func (s *stream) Aggregate(f Aggregator, iv interface{}, opt ...StreamOption) St
 	}
 	return s.append(func(it Iterator) (Executor, error) {
 		return NewAggregateExecutor(f, it, iv, aopts...)
-	}, c.NodeID)
+	}, c)
+}
+func (s *stream) AggregateWhile(f Aggregator, iv interface{}, cond Filter, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewAggregateWhileExecutor(f, cond, it, iv)
+	}, c)
+}
+func (s *stream) GroupBy(f Mapper, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewGroupByExecutor(f, it), nil
+	}, c)
+}
+func (s *stream) Scan(f Aggregator, iv interface{}, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewScanExecutor(f, it, iv)
+	}, c)
 }
 func (s *stream) Sort(f Comparator, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	copts := []ExecutorOption{WithExecutorMaxBuffer(c.MaxBuffer)}
+	if c.Sort.IsStrict {
+		copts = append(copts, WithCompareExecutorStrict(true))
+	}
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewCompareExecutor(f, it, copts...), nil
+	}, c)
+}
+func (s *stream) Concat(others []Iterator, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewConcatExecutor(it, others...), nil
+	}, c)
+}
+func (s *stream) Enumerate(opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewEnumerateExecutor(c.Enumerate.Start, it), nil
+	}, c)
+}
+func (s *stream) Reverse(opt ...StreamOption) Stream {
 	c := newStreamConfig(opt...)
 	return s.append(func(it Iterator) (Executor, error) {
-		return NewCompareExecutor(f, it), nil
-	}, c.NodeID)
+		return NewReverseExecutor(it, WithExecutorMaxBuffer(c.MaxBuffer)), nil
+	}, c)
 }
 func (s *stream) Flat(opt ...StreamOption) Stream {
 	c := newStreamConfig(opt...)
 	return s.append(func(it Iterator) (Executor, error) {
-		return NewFlatExecutor(it), nil
-	}, c.NodeID)
+		return NewFlatExecutor(it, WithFlatExecutorString(c.Flat.IsStringFlattened)), nil
+	}, c)
+}
+func (s *stream) FlatChannel(opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewFlatChannelExecutor(it), nil
+	}, c)
+}
+func (s *stream) FlatDeep(depth int, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewFlatDeepExecutor(depth, it, WithFlatExecutorTuple(c.Flat.IsTupleFlattened)), nil
+	}, c)
+}
+func (s *stream) Chunk(size int, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewChunkExecutor(size, it)
+	}, c)
+}
+func (s *stream) WindowAggregate(size int, f Aggregator, iv interface{}, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	aopts := []ExecutorOption{}
+	if c.Aggregate.Type != UnknownAggregateExecutorType {
+		aopts = append(aopts, WithAggregateExecutorType(c.Aggregate.Type))
+	}
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewWindowAggregateExecutor(size, f, it, iv, aopts...)
+	}, c)
+}
+func (s *stream) TopN(n int, f Comparator, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewTopNExecutor(n, f, it)
+	}, c)
+}
+func (s *stream) BottomN(n int, f Comparator, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewBottomNExecutor(n, f, it)
+	}, c)
+}
+func (s *stream) StepBy(n int, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewStepExecutor(n, it)
+	}, c)
+}
+func (s *stream) Window(size int, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewWindowExecutor(size, it)
+	}, c)
+}
+
+func (s *stream) Reduce(f Aggregator, iv interface{}, opt ...StreamOption) (interface{}, error) {
+	it, err := s.Aggregate(f, iv, opt...).Execute()
+	if err != nil {
+		return nil, err
+	}
+	return it.Next()
+}
+func (s *stream) FlatMap(f Mapper, opt ...StreamOption) Stream {
+	c := newStreamConfig(opt...)
+	return s.append(func(it Iterator) (Executor, error) {
+		return NewFlatMapExecutor(f, it), nil
+	}, c)
+}
+
+func (s *stream) Partition(f Filter) (Iterator, Iterator, error) {
+	it, err := s.Execute()
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewPartitionExecutor(f, it).PartitionExecute()
 }
 
 func (s *stream) Consume(f Consumer, opt ...StreamOption) error {
-	it, err := s.connect()
+	it, err := s.Execute()
 	if err != nil {
 		return err
 	}
 	return NewConsumeExecutor(f, it).ConsumeExecute()
 }
 
+func (s *stream) ConsumeWithContext(ctx context.Context, f Consumer, opt ...StreamOption) error {
+	it, err := s.Execute()
+	if err != nil {
+		return err
+	}
+	return NewConsumeExecutor(f, it).ConsumeExecuteWithContext(ctx)
+}
+
+func (s *stream) ConsumeBatch(f Consumer, size int, opt ...StreamOption) error {
+	it, err := s.Execute()
+	if err != nil {
+		return err
+	}
+	ex, err := NewBatchConsumeExecutor(f, it, size)
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	return ex.ConsumeExecute()
+}
+
+func (s *stream) ConsumeParallel(f Consumer, workers int, opt ...StreamOption) error {
+	c := newStreamConfig(opt...)
+	it, err := s.Execute()
+	if err != nil {
+		return err
+	}
+	ex, err := NewParallelConsumeExecutor(f, it, workers)
+	if err != nil {
+		return fmt.Errorf("%w %v", ErrCannotCreateStream, err)
+	}
+	if err := ex.ConsumeExecute(); err != nil {
+		nodeID := s.nodeID(c.NodeID)
+		if c.ErrorFormatter != nil {
+			return c.ErrorFormatter(nodeID, err)
+		}
+		return formatNodeError(nodeID, err)
+	}
+	return nil
+}
+
 type (
 	// StreamOption is an option of Stream.
 	StreamOption func(*StreamConfig)
@@ -135,11 +619,51 @@ type (
 	StreamConfig struct {
 		NodeID    string
 		Aggregate StreamConfigAggregate
+		Enumerate StreamConfigEnumerate
+		Sort      StreamConfigSort
+		Flat      StreamConfigFlat
+		Retry     StreamConfigRetry
+		// CollectErrors is true if Map and Filter collect the errors of the elements
+		// instead of ignoring or stopping on them.
+		CollectErrors bool
+		// ResumeOnError is true if Filter drops the elements that the function returns error for
+		// instead of stopping on them.
+		ResumeOnError bool
+		// Observer observes the node if not nil.
+		Observer NodeObserver
+		// OnComplete is called when the iteration of the stream ends if not nil.
+		OnComplete func(error)
+		// ApplyTimeout limits the time of each call of the function of Map and Filter if positive.
+		ApplyTimeout time.Duration
+		// MaxBuffer limits the number of the elements buffered by Sort and Reverse if positive.
+		MaxBuffer int
+		// ErrorFormatter converts the errors from the node if not nil.
+		ErrorFormatter func(nodeID string, err error) error
 	}
 	// StreamConfigAggregate is a config for Aggregate.
 	StreamConfigAggregate struct {
 		Type AggregateExecutorType
 	}
+	// StreamConfigEnumerate is a config for Enumerate.
+	StreamConfigEnumerate struct {
+		Start int
+	}
+
+	// StreamConfigFlat is a config for Flat and FlatDeep.
+	StreamConfigFlat struct {
+		IsTupleFlattened  bool
+		IsStringFlattened bool
+	}
+
+	// StreamConfigRetry is a config for MapRetry.
+	StreamConfigRetry struct {
+		Backoff func(attempt int) time.Duration
+	}
+
+	// StreamConfigSort is a config for Sort.
+	StreamConfigSort struct {
+		IsStrict bool
+	}
 
 	// AggregateType is a type of aggregation.
 	AggregateType int
@@ -180,6 +704,109 @@ func WithAggregateType(t AggregateType) StreamOption {
 	}
 }
 
+// WithEnumerateStart returns a new StreamOption that sets the first index of Enumerate.
+func WithEnumerateStart(start int) StreamOption {
+	return func(c *StreamConfig) {
+		c.Enumerate.Start = start
+	}
+}
+
+// WithFlatTuple returns a new StreamOption that makes FlatDeep flatten Tuples as well as slices and arrays.
+func WithFlatTuple() StreamOption {
+	return func(c *StreamConfig) {
+		c.Flat.IsTupleFlattened = true
+	}
+}
+
+// WithFlatString returns a new StreamOption that makes Flat flatten strings into runes.
+func WithFlatString() StreamOption {
+	return func(c *StreamConfig) {
+		c.Flat.IsStringFlattened = true
+	}
+}
+
+// WithRetryBackoff returns a new StreamOption that sets the interval before each retry of MapRetry.
+// attempt is the number of the failed attempts, starts from 1.
+func WithRetryBackoff(f func(attempt int) time.Duration) StreamOption {
+	return func(c *StreamConfig) {
+		c.Retry.Backoff = f
+	}
+}
+
+// WithStrictSort returns a new StreamOption that makes Sort abort on the error from the comparator.
+// The iterator of the sorted stream yields the first error of the comparator instead of the elements.
+func WithStrictSort() StreamOption {
+	return func(c *StreamConfig) {
+		c.Sort.IsStrict = true
+	}
+}
+
+// WithResumeOnError returns a new StreamOption that makes Filter drop the elements
+// that the function returns error for and continue, like Map.
+// By default, Filter stops streaming on the error.
+func WithResumeOnError() StreamOption {
+	return func(c *StreamConfig) {
+		c.ResumeOnError = true
+	}
+}
+
+// WithCollectErrors returns a new StreamOption that makes Map and Filter continue past the elements
+// that the function returns error for, and collect the errors.
+// The iterator of the stream and its IteratorChannel implement ErrorCollector,
+// Errors() returns the collected errors prefixed with the node id after the iteration.
+func WithCollectErrors() StreamOption {
+	return func(c *StreamConfig) {
+		c.CollectErrors = true
+	}
+}
+
+// WithObserver returns a new StreamOption that sets an observer of the node.
+// obs is called on every element and error that the node yields.
+// See NodeObserver.
+func WithObserver(obs NodeObserver) StreamOption {
+	return func(c *StreamConfig) {
+		c.Observer = obs
+	}
+}
+
+// WithOnComplete returns a new StreamOption that registers f to be called once
+// when the iterator of the whole stream ends, regardless of the node that the option is given to.
+// f receives the error that terminated the iteration, nil if the iteration ended normally.
+// f is called even when the stream is consumed via Channel(),
+// but not called if the stream is not consumed until the end.
+func WithOnComplete(f func(err error)) StreamOption {
+	return func(c *StreamConfig) {
+		c.OnComplete = f
+	}
+}
+
+// WithApplyTimeout returns a new StreamOption that limits the time of each call of the function of Map and Filter.
+// If the function does not return within d, the element is regarded as ErrApplyTimeout,
+// so Map drops the element and Filter stops the stream.
+// This costs a goroutine for each element, see NewTimeoutMapper().
+func WithApplyTimeout(d time.Duration) StreamOption {
+	return func(c *StreamConfig) {
+		c.ApplyTimeout = d
+	}
+}
+
+// WithMaxBuffer returns a new StreamOption that limits the number of the elements buffered by Sort and Reverse.
+// If the node receives more than n elements, the stream yields ErrBufferOverflow.
+func WithMaxBuffer(n int) StreamOption {
+	return func(c *StreamConfig) {
+		c.MaxBuffer = n
+	}
+}
+
+// WithErrorFormatter returns a new StreamOption that sets a function that converts the errors from the node.
+// f receives the node id and the original error, the stream yields the result of f instead.
+// By default, the error is NodeError.
+func WithErrorFormatter(f func(nodeID string, err error) error) StreamOption {
+	return func(c *StreamConfig) {
+		c.ErrorFormatter = f
+	}
+}
+
 // WithNodeID returns a new StreamOption that sets an id of the node.
 // The node id is useful for debugging stream.
 // The errors yielded from the iteration of the stream contains the node id.
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/berquerant/circle"
 
@@ -248,3 +249,294 @@ func TestStream(t *testing.T) {
 		t.Run(tc.title, tc.test)
 	}
 }
+
+type countingObserver struct {
+	emits  map[string]int
+	errors map[string]int
+}
+
+func newCountingObserver() *countingObserver {
+	return &countingObserver{
+		emits:  map[string]int{},
+		errors: map[string]int{},
+	}
+}
+
+func (s *countingObserver) OnEmit(nodeID string, _ interface{}) { s.emits[nodeID]++ }
+func (s *countingObserver) OnError(nodeID string, _ error)      { s.errors[nodeID]++ }
+
+func TestStreamObserver(t *testing.T) {
+	obs := newCountingObserver()
+	it, err := circle.NewStream(circle.MustNewIterator([]int{1, 2, 3, 4, 5, -1, 6})).
+		Map(mustNewMapper(t, func(x int) int { return x * 2 }), circle.WithObserver(obs), circle.WithNodeID("double")).
+		Filter(mustNewFilter(t, func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return x > 4, nil
+		}), circle.WithObserver(obs)).
+		Map(mustNewMapper(t, func(x int) int { return x })).
+		Execute()
+	assert.Nil(t, err)
+	got, err := iteratorToInts(it)
+	assert.Equal(t, "2 1 negative", err.Error())
+	assert.Equal(t, "", cmp.Diff([]int{6, 8, 10}, got))
+	assert.Equal(t, "", cmp.Diff(map[string]int{
+		"double": 6,
+		"1":      3,
+	}, obs.emits))
+	assert.Equal(t, "", cmp.Diff(map[string]int{
+		"1": 1,
+	}, obs.errors))
+}
+
+func TestStreamNodeError(t *testing.T) {
+	errNegative := errors.New("negative")
+	it, err := circle.NewStream(circle.MustNewIterator([]int{1, -1, 3})).
+		Filter(mustNewFilter(t, func(x int) (bool, error) {
+			if x < 0 {
+				return false, errNegative
+			}
+			return true, nil
+		}), circle.WithNodeID("positive")).
+		Execute()
+	assert.Nil(t, err)
+	got, err := iteratorToInts(it)
+	assert.Equal(t, "", cmp.Diff([]int{1}, got))
+	assert.Equal(t, "positive negative", err.Error())
+	var nerr circle.NodeError
+	assert.True(t, errors.As(err, &nerr))
+	assert.Equal(t, "positive", nerr.NodeID)
+	assert.Equal(t, errNegative, nerr.Err)
+	assert.True(t, errors.Is(err, errNegative))
+}
+
+func TestStreamMaxBuffer(t *testing.T) {
+	less := mustNewComparator(t, func(x, y int) bool { return x < y })
+	for _, tc := range []struct {
+		title   string
+		stream  func(circle.Stream) circle.Stream
+		want    []int
+		wantErr string
+	}{
+		{
+			title: "sort within buffer",
+			stream: func(s circle.Stream) circle.Stream {
+				return s.Sort(less, circle.WithMaxBuffer(3))
+			},
+			want: []int{1, 2, 3},
+		},
+		{
+			title: "sort overflow",
+			stream: func(s circle.Stream) circle.Stream {
+				return s.Sort(less, circle.WithMaxBuffer(2), circle.WithNodeID("sort"))
+			},
+			want:    []int{},
+			wantErr: "sort buffer overflow",
+		},
+		{
+			title: "reverse within buffer",
+			stream: func(s circle.Stream) circle.Stream {
+				return s.Reverse(circle.WithMaxBuffer(3))
+			},
+			want: []int{2, 1, 3},
+		},
+		{
+			title: "reverse overflow",
+			stream: func(s circle.Stream) circle.Stream {
+				return s.Reverse(circle.WithMaxBuffer(2), circle.WithNodeID("reverse"))
+			},
+			want:    []int{},
+			wantErr: "reverse buffer overflow",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			it, err := tc.stream(circle.NewStream(circle.MustNewIterator([]int{3, 1, 2}))).Execute()
+			assert.Nil(t, err)
+			c := it.Channel()
+			got := []int{}
+			for x := range c.C() {
+				got = append(got, x.(int))
+			}
+			assert.Equal(t, "", cmp.Diff(tc.want, got))
+			if tc.wantErr == "" {
+				assert.Nil(t, c.Err())
+				return
+			}
+			assert.Equal(t, tc.wantErr, c.Err().Error())
+			assert.True(t, errors.Is(c.Err(), circle.ErrBufferOverflow))
+		})
+	}
+}
+
+func TestStreamMapError(t *testing.T) {
+	newStream := func() circle.Stream {
+		return circle.NewStream(circle.MustNewIterator([]int{1, 2, -1, 3})).
+			Filter(mustNewFilter(t, func(x int) (bool, error) {
+				if x < 0 {
+					return false, errors.New("negative")
+				}
+				return true, nil
+			}), circle.WithNodeID("positive"))
+	}
+
+	t.Run("convert", func(t *testing.T) {
+		var calls int
+		it, err := newStream().
+			MapError(func(err error) error {
+				calls++
+				return fmt.Errorf("check input: %w", err)
+			}, circle.WithNodeID("context")).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+		assert.Equal(t, "context check input: positive negative", err.Error())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("suppress", func(t *testing.T) {
+		it, err := newStream().
+			MapError(func(error) error { return nil }).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+		assert.Equal(t, circle.ErrEOI, err)
+	})
+
+	t.Run("eoi", func(t *testing.T) {
+		var calls int
+		it, err := circle.NewStream(circle.MustNewIterator([]int{1, 2})).
+			MapError(func(err error) error {
+				calls++
+				return err
+			}).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func TestStreamApplyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	hang := func(x int) {
+		if x < 0 {
+			<-release
+		}
+	}
+
+	t.Run("map", func(t *testing.T) {
+		it, err := circle.NewStream(circle.MustNewIterator([]int{1, -1, 2})).
+			Map(mustNewMapper(t, func(x int) int {
+				hang(x)
+				return x
+			}), circle.WithApplyTimeout(50*time.Millisecond)).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		it, err := circle.NewStream(circle.MustNewIterator([]int{1, -1, 2})).
+			Filter(mustNewFilter(t, func(x int) bool {
+				hang(x)
+				return true
+			}), circle.WithApplyTimeout(50*time.Millisecond), circle.WithNodeID("filter")).
+			Execute()
+		assert.Nil(t, err)
+		got, err := iteratorToInts(it)
+		assert.True(t, errors.Is(err, circle.ErrApplyTimeout))
+		assert.Equal(t, "", cmp.Diff([]int{1}, got))
+	})
+}
+
+type testNodeError struct {
+	nodeID string
+	err    error
+}
+
+func (s *testNodeError) Error() string { return fmt.Sprintf("node=%s err=%v", s.nodeID, s.err) }
+func (s *testNodeError) Unwrap() error { return s.err }
+
+func TestStreamErrorFormatter(t *testing.T) {
+	errNegative := errors.New("negative")
+	it, err := circle.NewStream(circle.MustNewIterator([]int{1, 2, -1, 3})).
+		Filter(mustNewFilter(t, func(x int) (bool, error) {
+			if x < 0 {
+				return false, errNegative
+			}
+			return true, nil
+		}), circle.WithNodeID("positive"), circle.WithErrorFormatter(func(nodeID string, err error) error {
+			return &testNodeError{
+				nodeID: nodeID,
+				err:    err,
+			}
+		})).
+		Execute()
+	assert.Nil(t, err)
+	got, err := iteratorToInts(it)
+	assert.Equal(t, "", cmp.Diff([]int{1, 2}, got))
+	assert.Equal(t, "node=positive err=negative", err.Error())
+	var nerr *testNodeError
+	assert.True(t, errors.As(err, &nerr))
+	assert.Equal(t, "positive", nerr.nodeID)
+	assert.True(t, errors.Is(err, errNegative))
+}
+
+func TestStreamOnComplete(t *testing.T) {
+	t.Run("eoi", func(t *testing.T) {
+		var (
+			calls  int
+			gotErr error
+		)
+		it, err := circle.NewStream(circle.MustNewIterator([]int{1, 2, 3})).
+			Map(mustNewMapper(t, func(x int) int { return x * 2 }), circle.WithOnComplete(func(err error) {
+				calls++
+				gotErr = err
+			})).
+			Execute()
+		assert.Nil(t, err)
+		c := it.Channel()
+		got := []int{}
+		for x := range c.C() {
+			got = append(got, x.(int))
+		}
+		assert.Equal(t, "", cmp.Diff([]int{2, 4, 6}, got))
+		assert.Nil(t, it.Drain())
+		assert.Equal(t, 1, calls)
+		assert.Nil(t, gotErr)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var (
+			calls  int
+			gotErr error
+		)
+		it, err := circle.NewStream(circle.MustNewIterator([]int{1, -1, 3})).
+			Filter(mustNewFilter(t, func(x int) (bool, error) {
+				if x < 0 {
+					return false, errors.New("negative")
+				}
+				return true, nil
+			})).
+			Map(mustNewMapper(t, func(x int) int { return x }), circle.WithOnComplete(func(err error) {
+				calls++
+				gotErr = err
+			})).
+			Execute()
+		assert.Nil(t, err)
+		assert.NotNil(t, it.Drain())
+		_, err = it.Next()
+		assert.Equal(t, circle.ErrEOI, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "1 0 negative", gotErr.Error())
+	})
+}
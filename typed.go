@@ -0,0 +1,127 @@
+package circle
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TypedStream is a type-safe wrapper of StreamBuilder whose elements are A.
+//
+// The functions for TypedStream have typed signatures,
+// so invalid functions are rejected at compile time instead of ErrInvalidMapper or ErrInvalidFilter.
+//
+// MapTyped and FilterTyped do not modify the given stream, so a stream can be branched.
+// The branches share the source, so each of them can be collected
+// only if the source can be iterated again, e.g. NewTypedStreamFromSlice.
+// Otherwise, CollectTyped returns ErrNotReiterable after the source is consumed by a branch
+// instead of yielding nothing.
+type TypedStream[A any] struct {
+	b   StreamBuilder
+	src *typedSource
+}
+
+// typedSource records whether the source shared by the branches has been consumed.
+type typedSource struct {
+	mux        sync.Mutex
+	isConsumed bool
+}
+
+// consume marks the source consumed and returns false if it has been consumed already.
+func (s *typedSource) consume() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.isConsumed {
+		return false
+	}
+	s.isConsumed = true
+	return true
+}
+
+// NewTypedStream returns a new TypedStream.
+// The elements of it should be A.
+func NewTypedStream[A any](it Iterator) TypedStream[A] {
+	return TypedStream[A]{
+		b:   NewStreamBuilder(it),
+		src: &typedSource{},
+	}
+}
+
+// NewTypedStreamFromSlice returns a new TypedStream that yields the elements of xs.
+func NewTypedStreamFromSlice[A any](xs []A) TypedStream[A] {
+	return NewTypedStream[A](MustNewIterator(xs))
+}
+
+// Builder returns the underlying StreamBuilder.
+func (s TypedStream[A]) Builder() StreamBuilder { return s.b }
+
+// derive returns a new builder to append a node to, leaving s as it is.
+func (s TypedStream[A]) derive() StreamBuilder {
+	if b, ok := s.b.(*streamBuilder); ok {
+		return b.clone()
+	}
+	return s.b
+}
+
+// MapTyped maps s by f.
+// If f returns error, the element is filtered from the stream.
+// See StreamBuilder.Map().
+func MapTyped[A, B any](s TypedStream[A], f func(A) (B, error), opt ...StreamOption) TypedStream[B] {
+	return TypedStream[B]{
+		b:   s.derive().Map(f, opt...),
+		src: s.src,
+	}
+}
+
+// FilterTyped filters s by f.
+// If f returns error, stops streaming.
+// See StreamBuilder.Filter().
+func FilterTyped[A any](s TypedStream[A], f func(A) (bool, error), opt ...StreamOption) TypedStream[A] {
+	return TypedStream[A]{
+		b:   s.derive().Filter(f, opt...),
+		src: s.src,
+	}
+}
+
+// execute executes s from the beginning of the source if possible,
+// so that the other branches of s can be executed later.
+// If the source cannot be iterated again, executes s only once among the branches.
+func (s TypedStream[A]) execute() (Iterator, error) {
+	if b, ok := s.b.(*streamBuilder); ok && b.isReiterable() {
+		f, err := b.Build()
+		if err != nil {
+			return nil, err
+		}
+		return f()
+	}
+	if !s.src.consume() {
+		return nil, ErrNotReiterable
+	}
+	return s.b.Execute()
+}
+
+// CollectTyped returns all elements of s as a slice.
+// See Collect().
+// If an element is not A, returns ErrApply.
+func CollectTyped[A any](s TypedStream[A]) ([]A, error) {
+	it, err := s.execute()
+	if err != nil {
+		return nil, err
+	}
+	xs, err := Collect(it)
+	if err != nil {
+		return nil, err
+	}
+	r := make([]A, len(xs))
+	for i, x := range xs {
+		if x == nil {
+			// zero value of A
+			continue
+		}
+		v, ok := x.(A)
+		if !ok {
+			return nil, fmt.Errorf("%w %T", ErrApply, x)
+		}
+		r[i] = v
+	}
+	return r, nil
+}
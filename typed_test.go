@@ -0,0 +1,108 @@
+package circle_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/berquerant/circle"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func ExampleMapTyped() {
+	s := circle.NewTypedStreamFromSlice([]string{"1", "x", "3"})
+	ns := circle.MapTyped(s, strconv.Atoi)
+	evens := circle.FilterTyped(circle.MapTyped(ns, func(x int) (int, error) { return x * 2, nil }),
+		func(x int) (bool, error) { return x > 2, nil })
+	xs, err := circle.CollectTyped(evens)
+	fmt.Println(xs, err)
+	// Output:
+	// [6] <nil>
+}
+
+func TestTypedStream(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		xs, err := circle.CollectTyped(circle.NewTypedStreamFromSlice([]int{}))
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]int{}, xs))
+	})
+
+	t.Run("map", func(t *testing.T) {
+		s := circle.MapTyped(circle.NewTypedStreamFromSlice([]int{1, 2}), func(x int) (string, error) {
+			return strconv.Itoa(x * 10), nil
+		})
+		xs, err := circle.CollectTyped(s)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]string{"10", "20"}, xs))
+	})
+
+	t.Run("filter error", func(t *testing.T) {
+		s := circle.FilterTyped(circle.NewTypedStreamFromSlice([]int{1, -1}), func(x int) (bool, error) {
+			if x < 0 {
+				return false, errors.New("negative")
+			}
+			return true, nil
+		}, circle.WithNodeID("filter"))
+		_, err := circle.CollectTyped(s)
+		assert.Equal(t, "filter negative", fmt.Sprint(err))
+	})
+
+	t.Run("unexpected type", func(t *testing.T) {
+		s := circle.NewTypedStream[int](circle.MustNewIterator([]string{"x"}))
+		_, err := circle.CollectTyped(s)
+		assert.True(t, errors.Is(err, circle.ErrApply))
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		s := circle.MapTyped(circle.NewTypedStreamFromSlice([]int{1, 2, 3}), func(x int) (int, error) {
+			return x * 10, nil
+		})
+		odds := circle.FilterTyped(s, func(x int) (bool, error) { return x%20 != 0, nil })
+		strs := circle.MapTyped(s, func(x int) (string, error) { return strconv.Itoa(x), nil })
+
+		xs, err := circle.CollectTyped(s)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]int{10, 20, 30}, xs))
+		ys, err := circle.CollectTyped(odds)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]int{10, 30}, ys))
+		zs, err := circle.CollectTyped(strs)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]string{"10", "20", "30"}, zs))
+		// collect again
+		ys, err = circle.CollectTyped(odds)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]int{10, 30}, ys))
+	})
+
+	t.Run("branch not reiterable", func(t *testing.T) {
+		var i int
+		s := circle.NewTypedStream[int](circle.MustNewIterator(func() (interface{}, error) {
+			if i >= 3 {
+				return nil, circle.ErrEOI
+			}
+			i++
+			return i, nil
+		}))
+		odds := circle.FilterTyped(s, func(x int) (bool, error) { return x%2 == 1, nil })
+		strs := circle.MapTyped(s, func(x int) (string, error) { return strconv.Itoa(x), nil })
+
+		xs, err := circle.CollectTyped(odds)
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]int{1, 3}, xs))
+		_, err = circle.CollectTyped(strs)
+		assert.Equal(t, circle.ErrNotReiterable, err)
+		_, err = circle.CollectTyped(s)
+		assert.Equal(t, circle.ErrNotReiterable, err)
+	})
+
+	t.Run("builder", func(t *testing.T) {
+		s := circle.NewTypedStreamFromSlice([]int{3, 1, 2})
+		xs, err := s.Builder().Sort(func(x, y int) bool { return x < y }).Collect()
+		assert.Nil(t, err)
+		assert.Equal(t, "", cmp.Diff([]interface{}{1, 2, 3}, xs))
+	})
+}